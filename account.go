@@ -2,27 +2,29 @@ package tradier
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 )
 
 type Margin struct {
-	FedCall           int     `json:"fed_call"`
-	MaintenanceCall   int     `json:"maintenance_call"`
+	FedCall           float64 `json:"fed_call"`
+	MaintenanceCall   float64 `json:"maintenance_call"`
 	OptionBuyingPower float64 `json:"option_buying_power"`
 	StockBuyingPower  float64 `json:"stock_buying_power"`
 	StockShortValue   float64 `json:"stock_short_value"`
-	Sweep             int
+	Sweep             float64
 }
 
 type Cash struct {
 	CashAvailable  float64 `json:"cash_available"`
-	Sweep          int
+	Sweep          float64
 	UnsettledFunds float64 `json:"unsettled_funds"`
 }
 
 type PDT struct {
 	DayTradeBuyingPower float64 `json:"day_trade_buying_power"`
-	FedCall             int     `json:"fed_call"`
-	MaintenanceCall     int     `json:"maintenance_call"`
+	FedCall             float64 `json:"fed_call"`
+	MaintenanceCall     float64 `json:"maintenance_call"`
 	OptionBuyingPower   float64 `json:"option_buying_power"`
 	StockBuyingPower    float64 `json:"stock_buying_power"`
 	StockShortValue     float64 `json:"stock_short_value"`
@@ -167,11 +169,31 @@ type Order struct {
 	CreateDate        DateTime `json:"create_date"`
 	TransactionDate   DateTime `json:"transaction_date"`
 	Class             string
-	NumLegs           int `json:"num_legs"`
-	Legs              []Order
+	NumLegs           int       `json:"num_legs"`
+	Legs              OrderLegs `json:"leg"`
 	Strategy          string
 }
 
+// OrderLegs are the legs of a multileg, combo, OTO, OCO or OTOCO order.
+// Like other lists, Tradier sends a single object rather than a list if
+// there is only one leg.
+type OrderLegs []Order
+
+func (ol *OrderLegs) UnmarshalJSON(data []byte) error {
+	legs := make([]Order, 0)
+	if err := json.Unmarshal(data, &legs); err == nil {
+		*ol = legs
+		return nil
+	}
+
+	leg := Order{}
+	err := json.Unmarshal(data, &leg)
+	if err == nil {
+		*ol = []Order{leg}
+	}
+	return err
+}
+
 // If there is only a single event, then tradier sends back
 // an object, but if there are multiple events, then it sends
 // a list of objects...
@@ -224,11 +246,70 @@ func (oor *openOrdersResponse) UnmarshalJSON(data []byte) error {
 }
 
 type OrderPreview struct {
-	Commission    float64
-	Cost          float64
-	ExtendedHours bool `json:"extended_hours"`
-	Fees          float64
-	MarginChange  float64 `json:"margin_change"`
-	Quantity      float64
 	Status        string
+	Result        bool
+	Class         string
+	Strategy      string // Strategy detected by Tradier, e.g. "covered_call".
+	Symbol        string
+	Side          string
+	Type          string
+	Duration      string
+	Quantity      float64
+	Price         float64
+	StopPrice     float64  `json:"stop_price"`
+	ExtendedHours bool     `json:"extended_hours"`
+	RequestDate   DateTime `json:"request_date"`
+
+	Commission        float64
+	Cost              float64 // Order cost plus commission and fees.
+	Fees              float64
+	OrderCost         float64 `json:"order_cost"`
+	MarginChange      float64 `json:"margin_change"`
+	MarginRequirement float64 `json:"margin_requirement"`
+	OptionRequirement float64 `json:"option_requirement"`
+	DayTrades         int     `json:"day_trades"`
+
+	Warnings stringList `json:"warning"`
+	Errors   stringList `json:"error"`
+}
+
+// BuyingPowerEffect is the reduction in buying power if the order fills:
+// the margin change if Tradier reported one, otherwise the total cost
+// of the order. Tradier's cost already includes commission and fees.
+func (op *OrderPreview) BuyingPowerEffect() float64 {
+	if op.MarginChange != 0 {
+		return op.MarginChange
+	}
+	return op.Cost
+}
+
+// PreviewError is returned when Tradier rejects an order preview.
+type PreviewError struct {
+	Status string
+	Errors []string
+}
+
+func (pe *PreviewError) Error() string {
+	if len(pe.Errors) == 0 {
+		return fmt.Sprintf("received order status: %v", pe.Status)
+	}
+	return "order preview rejected: " + strings.Join(pe.Errors, "; ")
+}
+
+// Tradier sends a single string rather than a list if there is only one element.
+type stringList []string
+
+func (sl *stringList) UnmarshalJSON(data []byte) error {
+	strs := make([]string, 0)
+	if err := json.Unmarshal(data, &strs); err == nil {
+		*sl = strs
+		return nil
+	}
+
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		*sl = []string{s}
+	}
+	return err
 }
@@ -0,0 +1,262 @@
+package tradier
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+type AdjustmentMode string
+
+const (
+	// Prices as they traded, with no adjustment for splits or dividends.
+	AdjustNone AdjustmentMode = "none"
+	// Prices adjusted for splits only, as returned by the history endpoint.
+	AdjustSplits AdjustmentMode = "splits"
+	// Prices adjusted for splits and back-adjusted for dividends, so that
+	// returns computed from them are total returns.
+	AdjustTotalReturn AdjustmentMode = "total_return"
+)
+
+// AdjustmentFactor is a single split or dividend, and the factor by which
+// it multiplies unadjusted prices before its ex-date.
+type AdjustmentFactor struct {
+	ExDate time.Time
+	// Shares after the split per share before, or zero for dividends.
+	Split float64
+	// Cash paid per (unadjusted) share, or zero for splits.
+	Dividend float64
+	Factor   float64
+}
+
+func (af AdjustmentFactor) IsSplit() bool {
+	return af.Split != 0
+}
+
+// AdjustedHistory is a daily price history with the adjustments
+// that were applied to it.
+type AdjustedHistory struct {
+	Symbol  string
+	Mode    AdjustmentMode
+	History []TimeSale
+	// All splits and dividends that affect the history, sorted by ex-date.
+	Factors []AdjustmentFactor
+}
+
+// GetAdjustedHistory returns the daily price history of the symbol,
+// adjusted for splits and dividends according to mode. Splits are
+// fetched in every mode so that Factors lists them; dividends are
+// only fetched for AdjustTotalReturn.
+func (tc *Client) GetAdjustedHistory(symbol string, start, end time.Time, mode AdjustmentMode) (*AdjustedHistory, error) {
+	history, err := tc.GetTimeSales(symbol, IntervalDaily, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var dividends []CashDividend
+	if mode == AdjustTotalReturn {
+		resp, err := tc.GetDividends([]string{symbol})
+		if err != nil {
+			return nil, err
+		}
+		dividends = DividendsFromResponse(resp)[symbol]
+	}
+	actions, err := tc.GetCorporateActions([]string{symbol})
+	if err != nil {
+		return nil, err
+	}
+
+	return AdjustHistory(symbol, history, dividends,
+		SplitsFromCorporateActions(actions)[symbol], mode)
+}
+
+// DividendsFromResponse extracts the cash dividends of each requested symbol.
+func DividendsFromResponse(resp GetDividendsResponse) map[string][]CashDividend {
+	dividends := make(map[string][]CashDividend)
+	for _, r := range resp {
+		if r.Error != "" {
+			continue
+		}
+
+		for _, result := range r.Results {
+			dividends[r.Request] = append(dividends[r.Request], result.Tables.CashDividends...)
+		}
+	}
+
+	return dividends
+}
+
+// SplitsFromCorporateActions extracts the stock splits of each requested symbol.
+func SplitsFromCorporateActions(resp GetCorporateActionsResponse) map[string][]StockSplit {
+	splits := make(map[string][]StockSplit)
+	for _, r := range resp {
+		if r.Error != "" {
+			continue
+		}
+
+		for _, result := range r.Results {
+			for _, split := range result.Tables.StockSplits {
+				splits[r.Request] = append(splits[r.Request], split)
+			}
+		}
+	}
+
+	return splits
+}
+
+// AdjustHistory converts a split-adjusted daily history, as returned by
+// the history endpoint, according to mode.
+func AdjustHistory(symbol string, history []TimeSale,
+	dividends []CashDividend, splits []StockSplit, mode AdjustmentMode) (*AdjustedHistory, error) {
+	if mode != AdjustNone && mode != AdjustSplits && mode != AdjustTotalReturn {
+		return nil, fmt.Errorf("unknown adjustment mode: %v", mode)
+	}
+
+	history = append([]TimeSale{}, history...)
+	sort.SliceStable(history, func(i, j int) bool { return history[i].Date.Before(history[j].Date.Time) })
+
+	splitAdj, err := splitFactors(splits)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cumulative split factor of each bar, by which unadjusted prices
+	// were multiplied to give the split-adjusted history.
+	cumSplit := make([]float64, len(history))
+	for i, ts := range history {
+		cumSplit[i] = cumulativeFactor(splitAdj, ts.Date.Time)
+	}
+
+	var factors []AdjustmentFactor
+	for _, af := range splitAdj {
+		if len(history) > 0 && af.ExDate.After(history[0].Date.Time) {
+			factors = append(factors, af)
+		}
+	}
+
+	var dividendAdj []AdjustmentFactor
+	if mode == AdjustTotalReturn {
+		dividendAdj, err = dividendFactors(dividends, history, cumSplit)
+		if err != nil {
+			return nil, err
+		}
+		factors = append(factors, dividendAdj...)
+	}
+	sort.SliceStable(factors, func(i, j int) bool { return factors[i].ExDate.Before(factors[j].ExDate) })
+
+	adjusted := make([]TimeSale, len(history))
+	for i, ts := range history {
+		var priceFactor, volumeFactor float64
+		switch mode {
+		case AdjustNone:
+			priceFactor, volumeFactor = 1/cumSplit[i], cumSplit[i]
+		case AdjustSplits:
+			priceFactor, volumeFactor = 1, 1
+		case AdjustTotalReturn:
+			priceFactor, volumeFactor = cumulativeFactor(dividendAdj, ts.Date.Time), 1
+		}
+		adjusted[i] = scaleTimeSale(ts, priceFactor, volumeFactor)
+	}
+
+	return &AdjustedHistory{
+		Symbol:  symbol,
+		Mode:    mode,
+		History: adjusted,
+		Factors: factors,
+	}, nil
+}
+
+func splitFactors(splits []StockSplit) ([]AdjustmentFactor, error) {
+	var factors []AdjustmentFactor
+	seen := make(map[time.Time]bool)
+	for _, split := range splits {
+		if split.ExDate == nil {
+			continue
+		}
+		exDate, err := time.Parse("2006-01-02", *split.ExDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid split ex-date: %v", err)
+		}
+
+		var ratio float64
+		if split.SplitFrom != nil && split.SplitTo != nil && *split.SplitFrom > 0 && *split.SplitTo > 0 {
+			ratio = *split.SplitTo / *split.SplitFrom
+		} else if split.AdjustmentFactor != nil && *split.AdjustmentFactor > 0 {
+			ratio = 1 / *split.AdjustmentFactor
+		} else {
+			continue
+		}
+
+		if seen[exDate] {
+			continue
+		}
+		seen[exDate] = true
+		factors = append(factors, AdjustmentFactor{
+			ExDate: exDate,
+			Split:  ratio,
+			Factor: 1 / ratio,
+		})
+	}
+
+	return factors, nil
+}
+
+// Back-adjustment factor of each dividend paid during the history:
+// one less the dividend as a fraction of the prior close.
+func dividendFactors(dividends []CashDividend, history []TimeSale, cumSplit []float64) ([]AdjustmentFactor, error) {
+	var factors []AdjustmentFactor
+	seen := make(map[time.Time]bool)
+	for _, d := range dividends {
+		if d.ExDate == nil || d.CashAmount == nil || *d.CashAmount <= 0 {
+			continue
+		}
+		exDate, err := time.Parse("2006-01-02", *d.ExDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dividend ex-date: %v", err)
+		}
+		if seen[exDate] {
+			continue
+		}
+
+		// The last close before the ex-date, if within the history.
+		i := sort.Search(len(history), func(i int) bool { return !history[i].Date.Before(exDate) })
+		if i == 0 || i == len(history) {
+			continue
+		}
+		prevClose := float64(history[i-1].Close) / cumSplit[i-1]
+		if prevClose <= 0 {
+			continue
+		}
+
+		seen[exDate] = true
+		factors = append(factors, AdjustmentFactor{
+			ExDate:   exDate,
+			Dividend: *d.CashAmount,
+			Factor:   1 - *d.CashAmount/prevClose,
+		})
+	}
+
+	return factors, nil
+}
+
+// Product of the factors with ex-dates after t.
+func cumulativeFactor(factors []AdjustmentFactor, t time.Time) float64 {
+	result := 1.0
+	for _, af := range factors {
+		if af.ExDate.After(t) {
+			result *= af.Factor
+		}
+	}
+	return result
+}
+
+func scaleTimeSale(ts TimeSale, price, volume float64) TimeSale {
+	ts.Open *= FloatOrNaN(price)
+	ts.High *= FloatOrNaN(price)
+	ts.Low *= FloatOrNaN(price)
+	ts.Close *= FloatOrNaN(price)
+	ts.Price *= FloatOrNaN(price)
+	ts.Vwap *= FloatOrNaN(price)
+	ts.Volume = int64(float64(ts.Volume)*volume + 0.5)
+	return ts
+}
@@ -0,0 +1,160 @@
+package tradier
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func testDay(date string, close float64, volume int64) TimeSale {
+	ts := TimeSale{
+		Open:   FloatOrNaN(close),
+		High:   FloatOrNaN(close),
+		Low:    FloatOrNaN(close),
+		Close:  FloatOrNaN(close),
+		Volume: volume,
+	}
+	ts.Date.Set(date)
+	return ts
+}
+
+func float64Ptr(x float64) *float64 { return &x }
+func stringPtr(s string) *string    { return &s }
+
+// A split-adjusted history with a 2:1 split on Jan 4 and a $1
+// dividend going ex on Jan 5. Unadjusted, the stock closed at 100
+// and 102 on 1000 shares before the split.
+func testAdjustmentHistory() ([]TimeSale, []CashDividend, []StockSplit) {
+	history := []TimeSale{
+		testDay("2024-01-05", 51, 1000),
+		testDay("2024-01-02", 50, 2000),
+		testDay("2024-01-03", 51, 2000),
+		testDay("2024-01-04", 52, 1000),
+	}
+	dividends := []CashDividend{
+		{ExDate: stringPtr("2024-01-05"), CashAmount: float64Ptr(1)},
+		// Outside of the history.
+		{ExDate: stringPtr("2023-06-01"), CashAmount: float64Ptr(0.5)},
+	}
+	splits := []StockSplit{
+		{ExDate: stringPtr("2024-01-04"), SplitFrom: float64Ptr(1), SplitTo: float64Ptr(2)},
+		// Before the history, so already reflected in all of its prices.
+		{ExDate: stringPtr("2020-08-31"), AdjustmentFactor: float64Ptr(0.25)},
+	}
+	return history, dividends, splits
+}
+
+func TestAdjustHistory(t *testing.T) {
+	divFactor := 1 - 1.0/52
+	testCases := []struct {
+		mode    AdjustmentMode
+		closes  []float64
+		volumes []int64
+		factors []AdjustmentFactor
+	}{
+		{
+			AdjustNone,
+			[]float64{100, 102, 52, 51},
+			[]int64{1000, 1000, 1000, 1000},
+			[]AdjustmentFactor{
+				{ExDate: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC), Split: 2, Factor: 0.5},
+			},
+		},
+		{
+			AdjustSplits,
+			[]float64{50, 51, 52, 51},
+			[]int64{2000, 2000, 1000, 1000},
+			[]AdjustmentFactor{
+				{ExDate: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC), Split: 2, Factor: 0.5},
+			},
+		},
+		{
+			AdjustTotalReturn,
+			[]float64{50 * divFactor, 51 * divFactor, 52 * divFactor, 51},
+			[]int64{2000, 2000, 1000, 1000},
+			[]AdjustmentFactor{
+				{ExDate: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC), Split: 2, Factor: 0.5},
+				{ExDate: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), Dividend: 1, Factor: divFactor},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		history, dividends, splits := testAdjustmentHistory()
+		adjusted, err := AdjustHistory("XYZ", history, dividends, splits, tc.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if adjusted.Mode != tc.mode || len(adjusted.History) != len(tc.closes) {
+			t.Fatalf("%v: expected %d bars, got %+v", tc.mode, len(tc.closes), adjusted)
+		}
+		for i, ts := range adjusted.History {
+			assertFloat(t, string(tc.mode)+" close", float64(ts.Close), tc.closes[i])
+			assertFloat(t, string(tc.mode)+" open", float64(ts.Open), tc.closes[i])
+			if ts.Volume != tc.volumes[i] {
+				t.Errorf("%v: expected volume %v on %v, got %v", tc.mode, tc.volumes[i], ts.Date, ts.Volume)
+			}
+		}
+
+		if len(adjusted.Factors) != len(tc.factors) {
+			t.Fatalf("%v: expected factors %v, got %v", tc.mode, tc.factors, adjusted.Factors)
+		}
+		for i, af := range adjusted.Factors {
+			expected := tc.factors[i]
+			if !af.ExDate.Equal(expected.ExDate) || af.IsSplit() != expected.IsSplit() {
+				t.Errorf("%v: expected factor %+v, got %+v", tc.mode, expected, af)
+			}
+			assertFloat(t, "split", af.Split, expected.Split)
+			assertFloat(t, "dividend", af.Dividend, expected.Dividend)
+			assertFloat(t, "factor", af.Factor, expected.Factor)
+		}
+	}
+
+	// The input is left unmodified.
+	history, dividends, splits := testAdjustmentHistory()
+	AdjustHistory("XYZ", history, dividends, splits, AdjustNone)
+	if history[0].Close != 51 || history[1].Close != 50 {
+		t.Errorf("expected history to be unmodified, got %v", history)
+	}
+}
+
+func TestAdjustHistoryUnknownMode(t *testing.T) {
+	history, dividends, splits := testAdjustmentHistory()
+	if _, err := AdjustHistory("XYZ", history, dividends, splits, "dividends"); err == nil {
+		t.Error("expected error for unknown adjustment mode")
+	}
+}
+
+func TestGetAdjustedHistorySplits(t *testing.T) {
+	responses := map[string]string{
+		"/v1/markets/history": `{"history":{"day":[` +
+			`{"date":"2024-01-03","open":51.0,"high":51.0,"low":51.0,"close":51.0,"volume":2000},` +
+			`{"date":"2024-01-04","open":52.0,"high":52.0,"low":52.0,"close":52.0,"volume":1000}]}}`,
+		"/beta/markets/fundamentals/corporate_actions": `[{"request":"XYZ","type":"Symbol","results":[` +
+			`{"type":"Company","id":"0C000012XY","tables":{"stock_splits":{"0":` +
+			`{"ex_date":"2024-01-04","split_from":1.0,"split_to":2.0}}}}]}]`,
+	}
+	tc := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+
+	start := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	adjusted, err := tc.GetAdjustedHistory("XYZ", start, end, AdjustSplits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(adjusted.History) != 2 {
+		t.Fatalf("expected 2 bars, got %v", adjusted.History)
+	}
+	assertFloat(t, "close", float64(adjusted.History[0].Close), 51)
+	if len(adjusted.Factors) != 1 || adjusted.Factors[0].Split != 2 {
+		t.Errorf("expected the 2:1 split in factors, got %+v", adjusted.Factors)
+	}
+}
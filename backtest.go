@@ -0,0 +1,349 @@
+package tradier
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// BacktestFill selects the price at which a Backtest fills orders.
+type BacktestFill string
+
+const (
+	// Orders placed on a bar fill at the open of the next bar.
+	FillNextOpen BacktestFill = "next_open"
+	// Orders placed on a bar fill at the close of the next bar.
+	FillNextClose BacktestFill = "next_close"
+	// Orders placed on a bar fill at the close of that same bar. The
+	// strategy has already seen that close, so this has look-ahead bias.
+	FillSameClose BacktestFill = "same_close"
+)
+
+// BacktestParams configures a Backtest.
+type BacktestParams struct {
+	Symbols  []string
+	Interval Interval
+	Start    time.Time
+	End      time.Time
+	// Source of historical bars, typically a *BarStore so that
+	// the backtest runs offline.
+	Bars BarSource
+	// If set, only bars on days the market is open are replayed, and
+	// intraday bars outside of the regular session are skipped.
+	Calendar []MarketCalendar
+
+	// Parameters of the simulated broker. Clock and StatePath are ignored.
+	Broker PaperBrokerParams
+	// When orders fill. Defaults to FillNextOpen.
+	Fill BacktestFill
+
+	// Annual risk-free rate used to compute the Sharpe ratio.
+	RiskFreeRate float64
+}
+
+// BacktestContext is passed to the strategy with every bar.
+type BacktestContext struct {
+	// The simulated broker that orders should be placed with.
+	Broker Broker
+	// The current simulated time.
+	Time time.Time
+
+	history map[string][]Bar
+}
+
+// History returns up to the last n bars seen for the symbol, including
+// the current bar, oldest first. If n <= 0 all bars are returned.
+func (ctx *BacktestContext) History(symbol string, n int) []Bar {
+	bars := ctx.history[symbol]
+	if n > 0 && len(bars) > n {
+		bars = bars[len(bars)-n:]
+	}
+	return bars
+}
+
+// BacktestStrategy is called with each bar, in time order.
+type BacktestStrategy func(ctx *BacktestContext, bar Bar)
+
+// EquityPoint is the account value at a point in time.
+type EquityPoint struct {
+	Time   time.Time
+	Equity float64
+}
+
+// BacktestTrade is a single fill in a backtest.
+type BacktestTrade struct {
+	Time     time.Time
+	OrderId  int
+	Symbol   string
+	Side     string
+	Quantity float64
+	Price    float64
+}
+
+// BacktestMetrics are summary statistics of a backtest.
+type BacktestMetrics struct {
+	TotalReturn float64
+	CAGR        float64
+	// Annualized Sharpe ratio of daily returns.
+	Sharpe float64
+	// Largest peak-to-trough decline, as a fraction of the peak.
+	MaxDrawdown float64
+	// Annualized traded notional divided by average equity.
+	Turnover float64
+}
+
+// BacktestResult is the outcome of a Backtest.
+type BacktestResult struct {
+	EquityCurve []EquityPoint
+	Trades      []BacktestTrade
+	Metrics     BacktestMetrics
+}
+
+// Backtest replays historical bars into the strategy in time order,
+// simulating fills with a PaperBroker. By default orders placed on a bar
+// fill at the open of the next bar of their symbol; see BacktestFill.
+// Orders that are not marketable then (e.g. limit orders) are checked
+// against the close of each subsequent bar.
+func Backtest(params BacktestParams, strategy BacktestStrategy) (*BacktestResult, error) {
+	if params.Bars == nil {
+		return nil, fmt.Errorf("backtest requires a bar source")
+	}
+	switch params.Fill {
+	case "":
+		params.Fill = FillNextOpen
+	case FillNextOpen, FillNextClose, FillSameClose:
+	default:
+		return nil, fmt.Errorf("unknown backtest fill: %v", params.Fill)
+	}
+
+	bars, err := loadBacktestBars(params)
+	if err != nil {
+		return nil, err
+	}
+
+	now := params.Start
+	brokerParams := params.Broker
+	brokerParams.StatePath = ""
+	brokerParams.Clock = func() time.Time { return now }
+	if params.Fill != FillSameClose && brokerParams.Latency <= 0 {
+		// Orders placed at the current time cannot fill until
+		// the clock moves on to the next bar.
+		brokerParams.Latency = time.Nanosecond
+	}
+	broker, err := NewPaperBroker(brokerParams)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &BacktestContext{
+		Broker:  broker,
+		history: make(map[string][]Bar),
+	}
+	result := &BacktestResult{}
+	for start := 0; start < len(bars); {
+		end := start + 1
+		for end < len(bars) && bars[end].Time.Equal(bars[start].Time) {
+			end++
+		}
+		group := bars[start:end]
+		start = end
+
+		// Update the market for every symbol with a bar at this time
+		// before the strategy sees any of them, so that pending orders
+		// fill at this time's prices regardless of replay order.
+		now = group[0].Time
+		ctx.Time = now
+		var opens, closes []*Quote
+		for _, bar := range group {
+			ctx.history[bar.Symbol] = append(ctx.history[bar.Symbol], bar)
+			if params.Fill == FillNextOpen && bar.Open > 0 {
+				opens = append(opens, barQuote(bar.Symbol, bar.Open))
+			}
+			closes = append(closes, barQuote(bar.Symbol, bar.Close))
+		}
+		if len(opens) > 0 {
+			broker.SetQuotes(opens)
+		}
+		broker.SetQuotes(closes)
+
+		for _, bar := range group {
+			strategy(ctx, bar)
+		}
+
+		balances, err := broker.GetAccountBalances()
+		if err != nil {
+			return nil, err
+		}
+		result.EquityCurve = append(result.EquityCurve, EquityPoint{now, balances.TotalEquity})
+	}
+
+	orders, err := broker.GetOpenOrders()
+	if err != nil {
+		return nil, err
+	}
+	result.Trades = backtestTrades(orders)
+	result.Metrics = computeMetrics(result.EquityCurve, result.Trades,
+		params.Broker.InitialCash, params.RiskFreeRate)
+	return result, nil
+}
+
+func barQuote(symbol string, price float64) *Quote {
+	return &Quote{
+		Symbol: symbol,
+		Bid:    price,
+		Ask:    price,
+		Last:   price,
+	}
+}
+
+// Load the bars for all symbols, restricted to market hours, and merge
+// them into a single time-ordered series.
+func loadBacktestBars(params BacktestParams) ([]Bar, error) {
+	sessions := make(map[string]MarketCalendar, len(params.Calendar))
+	for _, day := range params.Calendar {
+		sessions[day.Date.Format("2006-01-02")] = day
+	}
+
+	var all []Bar
+	for _, symbol := range params.Symbols {
+		tss, err := params.Bars.GetTimeSales(symbol, params.Interval, params.Start, params.End)
+		if err != nil {
+			return nil, fmt.Errorf("error loading bars for %v: %v", symbol, err)
+		}
+
+		for _, bar := range BarsFromTimeSales(symbol, tss) {
+			if len(sessions) == 0 || inSession(bar, params.Interval, sessions) {
+				all = append(all, bar)
+			}
+		}
+	}
+
+	// Stable so that bars at the same time are replayed in symbol order.
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Time.Before(all[j].Time) })
+	return all, nil
+}
+
+func inSession(bar Bar, interval Interval, sessions map[string]MarketCalendar) bool {
+	day, ok := sessions[bar.Time.In(MarketLocation()).Format("2006-01-02")]
+	if !ok {
+		return false
+	}
+	td := ParseTradingDay(day)
+	if IsDailyInterval(interval) {
+		return td.Open
+	}
+	return td.SessionAt(bar.Time).State == MarketOpen
+}
+
+func backtestTrades(orders []*Order) []BacktestTrade {
+	var trades []BacktestTrade
+	for _, o := range orders {
+		if o.Status != Filled {
+			continue
+		}
+
+		if o.Class == Multileg || o.Class == Combo {
+			for _, leg := range o.Legs {
+				trades = append(trades, BacktestTrade{
+					Time:     o.TransactionDate.Time,
+					OrderId:  o.Id,
+					Symbol:   legSymbol(leg),
+					Side:     leg.Side,
+					Quantity: leg.ExecutedQuantity,
+					Price:    leg.AverageFillPrice,
+				})
+			}
+			continue
+		}
+
+		trades = append(trades, BacktestTrade{
+			Time:     o.TransactionDate.Time,
+			OrderId:  o.Id,
+			Symbol:   legSymbol(*o),
+			Side:     o.Side,
+			Quantity: o.ExecutedQuantity,
+			Price:    o.AverageFillPrice,
+		})
+	}
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].Time.Before(trades[j].Time) })
+	return trades
+}
+
+func computeMetrics(curve []EquityPoint, trades []BacktestTrade, initial, riskFreeRate float64) BacktestMetrics {
+	var m BacktestMetrics
+	if len(curve) == 0 || initial <= 0 {
+		return m
+	}
+
+	final := curve[len(curve)-1].Equity
+	m.TotalReturn = final/initial - 1
+	years := curve[len(curve)-1].Time.Sub(curve[0].Time).Hours() / 24 / 365.25
+	if years > 0 && final > 0 {
+		m.CAGR = math.Pow(final/initial, 1/years) - 1
+	}
+
+	peak := initial
+	var sumEquity float64
+	for _, p := range curve {
+		peak = math.Max(peak, p.Equity)
+		if peak > 0 {
+			m.MaxDrawdown = math.Max(m.MaxDrawdown, (peak-p.Equity)/peak)
+		}
+		sumEquity += p.Equity
+	}
+
+	returns := dailyReturns(curve, initial)
+	if len(returns) > 1 {
+		dailyRf := riskFreeRate / tradingDaysPerYear
+		var mean float64
+		for _, r := range returns {
+			mean += r - dailyRf
+		}
+		mean /= float64(len(returns))
+		var variance float64
+		for _, r := range returns {
+			d := r - dailyRf - mean
+			variance += d * d
+		}
+		std := math.Sqrt(variance / float64(len(returns)-1))
+		if std > 0 {
+			m.Sharpe = mean / std * math.Sqrt(tradingDaysPerYear)
+		}
+	}
+
+	var notional float64
+	for _, t := range trades {
+		notional += math.Abs(t.Quantity * t.Price * contractMultiplier(t.Symbol))
+	}
+	avgEquity := sumEquity / float64(len(curve))
+	if avgEquity > 0 {
+		m.Turnover = notional / avgEquity
+		if years > 0 {
+			m.Turnover /= years
+		}
+	}
+
+	return m
+}
+
+const tradingDaysPerYear = 252
+
+// Returns between the final equity of successive days.
+func dailyReturns(curve []EquityPoint, initial float64) []float64 {
+	var returns []float64
+	prev := initial
+	for i, p := range curve {
+		date := p.Time.In(MarketLocation()).Format("2006-01-02")
+		lastOfDay := i+1 == len(curve) ||
+			curve[i+1].Time.In(MarketLocation()).Format("2006-01-02") != date
+		if !lastOfDay {
+			continue
+		}
+		if prev > 0 {
+			returns = append(returns, p.Equity/prev-1)
+		}
+		prev = p.Equity
+	}
+	return returns
+}
@@ -0,0 +1,89 @@
+package tradier
+
+import (
+	"testing"
+	"time"
+)
+
+// A BarSource serving fixed time sales for each symbol.
+type testBars map[string][]TimeSale
+
+func (tb testBars) GetTimeSales(symbol string, interval Interval, start, end time.Time) ([]TimeSale, error) {
+	return tb[symbol], nil
+}
+
+func dailyTimeSale(date string, open, close float64) TimeSale {
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		panic(err)
+	}
+	return TimeSale{Date: DateTime{d}, Open: FloatOrNaN(open), Close: FloatOrNaN(close)}
+}
+
+// Orders for one symbol must fill at that symbol's next bar, even when
+// another symbol's bars are replayed first or more often.
+func TestBacktestMultiSymbolFills(t *testing.T) {
+	b := []TimeSale{
+		dailyTimeSale("2024-01-02", 100, 101),
+		dailyTimeSale("2024-01-03", 102, 103),
+		dailyTimeSale("2024-01-04", 104, 105),
+	}
+	everyDay := []TimeSale{
+		dailyTimeSale("2024-01-02", 10, 11),
+		dailyTimeSale("2024-01-03", 12, 13),
+		dailyTimeSale("2024-01-04", 14, 15),
+	}
+	missingDay := []TimeSale{everyDay[0], everyDay[2]}
+
+	testCases := []struct {
+		name     string
+		a        []TimeSale
+		fill     BacktestFill
+		expected float64
+	}{
+		{"next open", everyDay, FillNextOpen, 12},
+		{"next close", everyDay, FillNextClose, 13},
+		{"same close", everyDay, FillSameClose, 11},
+		{"next open after missing bar", missingDay, FillNextOpen, 14},
+		{"next close after missing bar", missingDay, FillNextClose, 15},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			params := BacktestParams{
+				// B is replayed before A at each time.
+				Symbols:  []string{"B", "A"},
+				Interval: IntervalDaily,
+				Bars:     testBars{"A": tc.a, "B": b},
+				Broker:   PaperBrokerParams{InitialCash: 10000},
+				Fill:     tc.fill,
+			}
+			placed := false
+			result, err := Backtest(params, func(ctx *BacktestContext, bar Bar) {
+				if bar.Symbol != "A" || placed {
+					return
+				}
+				placed = true
+				_, err := ctx.Broker.PlaceOrder(Order{
+					Class:    Equity,
+					Symbol:   "A",
+					Side:     Buy,
+					Quantity: 1,
+					Type:     MarketOrder,
+					Duration: Day,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Trades) != 1 {
+				t.Fatalf("expected 1 trade, got %v", result.Trades)
+			}
+			assertFloat(t, "fill price", result.Trades[0].Price, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package tradier
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// Account types.
+	AccountTypeCash   = "cash"
+	AccountTypeMargin = "margin"
+	AccountTypePDT    = "pdt"
+)
+
+// TypedBalances is the balances of an account, interpreted according to
+// its account type. It is implemented by *CashBalances, *MarginBalances
+// and *PDTBalances.
+type TypedBalances interface {
+	// The raw balances as returned by Tradier.
+	Balances() *AccountBalances
+	// The buying power available for orders of the given class.
+	BuyingPower(class string) float64
+	// The amount of cash that can be withdrawn from the account.
+	AvailableToWithdraw() float64
+	// Whether the account has an outstanding fed or maintenance call.
+	HasCall() bool
+}
+
+// CashBalances are the balances of a cash account.
+type CashBalances struct {
+	AccountBalances
+}
+
+func (b *CashBalances) Balances() *AccountBalances {
+	return &b.AccountBalances
+}
+
+// Cash accounts may only trade with settled cash, regardless of class.
+func (b *CashBalances) BuyingPower(class string) float64 {
+	return b.Cash.CashAvailable
+}
+
+func (b *CashBalances) AvailableToWithdraw() float64 {
+	return math.Max(0, b.Cash.CashAvailable)
+}
+
+func (b *CashBalances) HasCall() bool {
+	return false
+}
+
+// MarginBalances are the balances of a margin account.
+type MarginBalances struct {
+	AccountBalances
+}
+
+func (b *MarginBalances) Balances() *AccountBalances {
+	return &b.AccountBalances
+}
+
+func (b *MarginBalances) BuyingPower(class string) float64 {
+	return marginBuyingPower(class, b.Margin.StockBuyingPower, b.Margin.OptionBuyingPower)
+}
+
+func (b *MarginBalances) AvailableToWithdraw() float64 {
+	return marginWithdrawable(&b.AccountBalances, b.HasCall())
+}
+
+func (b *MarginBalances) HasCall() bool {
+	return b.Margin.FedCall > 0 || b.Margin.MaintenanceCall > 0
+}
+
+// PDTBalances are the balances of a pattern day trader account.
+type PDTBalances struct {
+	AccountBalances
+}
+
+func (b *PDTBalances) Balances() *AccountBalances {
+	return &b.AccountBalances
+}
+
+// Overnight buying power; see DayTradeBuyingPower for intraday.
+func (b *PDTBalances) BuyingPower(class string) float64 {
+	return marginBuyingPower(class, b.PDT.StockBuyingPower, b.PDT.OptionBuyingPower)
+}
+
+func (b *PDTBalances) DayTradeBuyingPower() float64 {
+	return b.PDT.DayTradeBuyingPower
+}
+
+func (b *PDTBalances) AvailableToWithdraw() float64 {
+	return marginWithdrawable(&b.AccountBalances, b.HasCall())
+}
+
+func (b *PDTBalances) HasCall() bool {
+	return b.PDT.FedCall > 0 || b.PDT.MaintenanceCall > 0
+}
+
+func marginBuyingPower(class string, stock, option float64) float64 {
+	switch class {
+	case Option, Multileg, Combo:
+		return option
+	default:
+		return stock
+	}
+}
+
+// Cash may be withdrawn from a margin account as long as it does not
+// reduce equity below the current margin requirement.
+func marginWithdrawable(b *AccountBalances, hasCall bool) float64 {
+	if hasCall {
+		return 0
+	}
+	excess := b.TotalEquity - b.CurrentRequirement
+	return math.Max(0, math.Min(b.TotalCash, excess))
+}
+
+// NewTypedBalances returns the typed variant of the given balances
+// based on its AccountType.
+func NewTypedBalances(b *AccountBalances) (TypedBalances, error) {
+	if b == nil {
+		return nil, fmt.Errorf("no account balances")
+	}
+
+	switch b.AccountType {
+	case AccountTypeCash:
+		return &CashBalances{*b}, nil
+	case AccountTypeMargin:
+		return &MarginBalances{*b}, nil
+	case AccountTypePDT:
+		return &PDTBalances{*b}, nil
+	default:
+		return nil, fmt.Errorf("unknown account type: %v", b.AccountType)
+	}
+}
+
+// Get the balances of the selected account, typed according to its account type.
+func (tc *Client) GetTypedAccountBalances() (TypedBalances, error) {
+	balances, err := tc.GetAccountBalances()
+	if err != nil {
+		return nil, err
+	}
+	return NewTypedBalances(balances)
+}
@@ -0,0 +1,95 @@
+package tradier
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTypedAccountBalances(t *testing.T) {
+	testCases := []struct {
+		fixture           string
+		accountType       string
+		stockBuyingPower  float64
+		optionBuyingPower float64
+		withdrawable      float64
+		hasCall           bool
+		sweep             float64
+		fedCall           float64
+		maintenanceCall   float64
+	}{
+		{
+			fixture:           "balances_cash.json",
+			accountType:       AccountTypeCash,
+			stockBuyingPower:  4343.38,
+			optionBuyingPower: 4343.38,
+			withdrawable:      4343.38,
+			sweep:             12.5,
+		},
+		{
+			fixture:           "balances_margin.json",
+			accountType:       AccountTypeMargin,
+			stockBuyingPower:  12727.72,
+			optionBuyingPower: 6363.86,
+			withdrawable:      6363.86,
+			sweep:             0.25,
+		},
+		{
+			fixture:         "balances_pdt.json",
+			accountType:     AccountTypePDT,
+			withdrawable:    0,
+			hasCall:         true,
+			fedCall:         1250.5,
+			maintenanceCall: 2749.25,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.accountType, func(t *testing.T) {
+			client := newTestClient(t, serveFixture(t, tc.fixture))
+			balances, err := client.GetTypedAccountBalances()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := balances.Balances().AccountType; got != tc.accountType {
+				t.Errorf("account type: got %v, expected %v", got, tc.accountType)
+			}
+			assertFloat(t, "stock buying power", balances.BuyingPower(Equity), tc.stockBuyingPower)
+			assertFloat(t, "option buying power", balances.BuyingPower(Option), tc.optionBuyingPower)
+			assertFloat(t, "available to withdraw", balances.AvailableToWithdraw(), tc.withdrawable)
+			if got := balances.HasCall(); got != tc.hasCall {
+				t.Errorf("has call: got %v, expected %v", got, tc.hasCall)
+			}
+
+			switch b := balances.(type) {
+			case *CashBalances:
+				assertFloat(t, "sweep", b.Cash.Sweep, tc.sweep)
+			case *MarginBalances:
+				assertFloat(t, "sweep", b.Margin.Sweep, tc.sweep)
+				assertFloat(t, "fed call", b.Margin.FedCall, tc.fedCall)
+				assertFloat(t, "maintenance call", b.Margin.MaintenanceCall, tc.maintenanceCall)
+			case *PDTBalances:
+				assertFloat(t, "fed call", b.PDT.FedCall, tc.fedCall)
+				assertFloat(t, "maintenance call", b.PDT.MaintenanceCall, tc.maintenanceCall)
+			default:
+				t.Errorf("unexpected balances type %T", balances)
+			}
+		})
+	}
+}
+
+func TestNewTypedBalancesUnknownType(t *testing.T) {
+	if _, err := NewTypedBalances(&AccountBalances{AccountType: "ira"}); err == nil {
+		t.Error("expected error for unknown account type")
+	}
+	if _, err := NewTypedBalances(nil); err == nil {
+		t.Error("expected error for nil balances")
+	}
+}
+
+func assertFloat(t *testing.T, name string, got, expected float64) {
+	t.Helper()
+	if math.Abs(got-expected) > 1e-9 {
+		t.Errorf("%v: got %v, expected %v", name, got, expected)
+	}
+}
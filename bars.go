@@ -0,0 +1,78 @@
+package tradier
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Bar is a single price bar (or tick) for a symbol, with its time
+// resolved to an absolute instant.
+type Bar struct {
+	Symbol string
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume int64
+	Vwap   float64
+}
+
+// IsDailyInterval returns true for intervals served by the history
+// endpoint (daily, weekly, monthly) rather than time sales.
+func IsDailyInterval(interval Interval) bool {
+	return interval == IntervalDaily || interval == IntervalWeekly || interval == IntervalMonthly
+}
+
+// TimeSaleTime returns the instant of the given time sale. Intraday time
+// sales carry a Unix timestamp; their Time field is wall clock time in
+// the market timezone. Daily bars only have a Date, and are considered
+// to occur at midnight in the market timezone.
+func TimeSaleTime(ts TimeSale) time.Time {
+	if ts.Timestamp > 0 {
+		return time.Unix(ts.Timestamp, 0)
+	}
+	if !ts.Time.IsZero() {
+		return inMarketLocation(ts.Time.Time)
+	}
+	return inMarketLocation(ts.Date.Time)
+}
+
+// Reinterpret a wall clock time parsed as UTC as being in the market timezone.
+func inMarketLocation(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(),
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), MarketLocation())
+}
+
+// NewBar converts a time sale into a Bar. Ticks, which only have a
+// Price, become bars with equal open, high, low and close. Missing
+// prices in bars are left as NaN.
+func NewBar(symbol string, ts TimeSale) Bar {
+	bar := Bar{
+		Symbol: symbol,
+		Time:   TimeSaleTime(ts),
+		Open:   float64(ts.Open),
+		High:   float64(ts.High),
+		Low:    float64(ts.Low),
+		Close:  float64(ts.Close),
+		Volume: ts.Volume,
+		Vwap:   float64(ts.Vwap),
+	}
+
+	price := float64(ts.Price)
+	if (bar.Close == 0 || math.IsNaN(bar.Close)) && price != 0 && !math.IsNaN(price) {
+		bar.Open, bar.High, bar.Low, bar.Close = price, price, price, price
+	}
+	return bar
+}
+
+// BarsFromTimeSales converts time sales into bars sorted by time.
+func BarsFromTimeSales(symbol string, tss []TimeSale) []Bar {
+	bars := make([]Bar, len(tss))
+	for i, ts := range tss {
+		bars[i] = NewBar(symbol, ts)
+	}
+	sort.SliceStable(bars, func(i, j int) bool { return bars[i].Time.Before(bars[j].Time) })
+	return bars
+}
@@ -0,0 +1,424 @@
+package tradier
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BarSource provides historical time sales for a symbol.
+// It is implemented by *BarStore for offline use.
+type BarSource interface {
+	GetTimeSales(symbol string, interval Interval, start, end time.Time) ([]TimeSale, error)
+}
+
+var (
+	_ BarSource = (*Client)(nil)
+	_ BarSource = (*BarStore)(nil)
+)
+
+// BarStore is an on-disk store of time sales under a root directory.
+// Daily, weekly and monthly bars are kept in one JSON file per symbol and
+// interval, and intraday time sales in one file per symbol, interval and
+// trading day, so that adding a day does not rewrite the whole history.
+// Sync downloads missing data from Tradier, so that minute and tick
+// history can be accumulated beyond the window that Tradier itself keeps.
+type BarStore struct {
+	client *Client
+	dir    string
+
+	calendar *TradingCalendar
+
+	mu sync.Mutex
+}
+
+// NewBarStore opens the store rooted at dir, creating it if necessary.
+// The client is used by Sync and may be nil if the store is only read.
+func NewBarStore(client *Client, dir string) (*BarStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &BarStore{
+		client:   client,
+		dir:      dir,
+		calendar: NewTradingCalendar(client),
+	}, nil
+}
+
+func (bs *BarStore) symbolDir(symbol string) string {
+	// Index symbols such as $SPX.X contain characters best avoided in paths.
+	name := strings.NewReplacer("/", "_", "$", "_").Replace(symbol)
+	return filepath.Join(bs.dir, name)
+}
+
+// Path of the file holding all bars of a daily interval. Intraday time
+// sales were also stored this way before they were split by day.
+func (bs *BarStore) path(symbol string, interval Interval) string {
+	return filepath.Join(bs.symbolDir(symbol), string(interval)+".json")
+}
+
+// Directory of the per-day files of an intraday interval.
+func (bs *BarStore) dayDir(symbol string, interval Interval) string {
+	return filepath.Join(bs.symbolDir(symbol), string(interval))
+}
+
+func (bs *BarStore) dayPath(symbol string, interval Interval, date string) string {
+	return filepath.Join(bs.dayDir(symbol, interval), date+".json")
+}
+
+// Path of the list of trading days that have been completely synced.
+func (bs *BarStore) syncedPath(symbol string, interval Interval) string {
+	return strings.TrimSuffix(bs.path(symbol, interval), ".json") + ".synced.json"
+}
+
+// GetTimeSales returns the stored time sales for the symbol in [start, end],
+// sorted by time. A zero start or end leaves that side unbounded.
+func (bs *BarStore) GetTimeSales(symbol string, interval Interval, start, end time.Time) ([]TimeSale, error) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	all, err := bs.load(symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	lo := 0
+	if !start.IsZero() {
+		lo = sort.Search(len(all), func(i int) bool { return !TimeSaleTime(all[i]).Before(start) })
+	}
+	hi := len(all)
+	if !end.IsZero() {
+		hi = sort.Search(len(all), func(i int) bool { return TimeSaleTime(all[i]).After(end) })
+	}
+	if lo >= hi {
+		return nil, nil
+	}
+	return all[lo:hi], nil
+}
+
+// Put merges the given time sales into the store. Existing entries
+// with the same time are replaced, except for ticks, of which there
+// may be several at the same time.
+func (bs *BarStore) Put(symbol string, interval Interval, tss []TimeSale) error {
+	if len(tss) == 0 {
+		return nil
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if IsDailyInterval(interval) {
+		path := bs.path(symbol, interval)
+		existing, err := loadTimeSales(path)
+		if err != nil {
+			return fmt.Errorf("error reading bars for %v (%v): %v", symbol, interval, err)
+		}
+		return saveTimeSales(path, mergeTimeSales(existing, tss, interval))
+	}
+
+	return bs.putDays(symbol, interval, tss)
+}
+
+// Merge intraday time sales into the files for their trading days.
+// Must be called with bs.mu held.
+func (bs *BarStore) putDays(symbol string, interval Interval, tss []TimeSale) error {
+	byDate := make(map[string][]TimeSale)
+	for _, ts := range tss {
+		date := marketDate(TimeSaleTime(ts))
+		byDate[date] = append(byDate[date], ts)
+	}
+
+	for date, day := range byDate {
+		path := bs.dayPath(symbol, interval, date)
+		existing, err := loadTimeSales(path)
+		if err != nil {
+			return fmt.Errorf("error reading bars for %v (%v) on %v: %v", symbol, interval, date, err)
+		}
+		if err := saveTimeSales(path, mergeTimeSales(existing, day, interval)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The date of t in the market timezone.
+func marketDate(t time.Time) string {
+	return t.In(MarketLocation()).Format("2006-01-02")
+}
+
+// Merge two lists of time sales, sorted by time, de-duplicating bars that
+// appear in both. Entries in newer take precedence.
+func mergeTimeSales(older, newer []TimeSale, interval Interval) []TimeSale {
+	if interval == IntervalTick {
+		// Ticks can't be de-duplicated by time alone.
+		seen := make(map[tickKey]bool, len(older))
+		for _, ts := range older {
+			seen[newTickKey(ts)] = true
+		}
+		merged := append([]TimeSale{}, older...)
+		for _, ts := range newer {
+			if key := newTickKey(ts); !seen[key] {
+				seen[key] = true
+				merged = append(merged, ts)
+			}
+		}
+		sortTimeSales(merged)
+		return merged
+	}
+
+	byTime := make(map[int64]TimeSale, len(older)+len(newer))
+	for _, ts := range older {
+		byTime[TimeSaleTime(ts).UnixNano()] = ts
+	}
+	for _, ts := range newer {
+		byTime[TimeSaleTime(ts).UnixNano()] = ts
+	}
+	merged := make([]TimeSale, 0, len(byTime))
+	for _, ts := range byTime {
+		merged = append(merged, ts)
+	}
+	sortTimeSales(merged)
+	return merged
+}
+
+type tickKey struct {
+	time   int64
+	price  uint64
+	volume int64
+}
+
+func newTickKey(ts TimeSale) tickKey {
+	return tickKey{
+		time:   TimeSaleTime(ts).UnixNano(),
+		price:  math.Float64bits(float64(ts.Price)),
+		volume: ts.Volume,
+	}
+}
+
+func sortTimeSales(tss []TimeSale) {
+	sort.SliceStable(tss, func(i, j int) bool {
+		return TimeSaleTime(tss[i]).Before(TimeSaleTime(tss[j]))
+	})
+}
+
+// Load the stored time sales that may fall in [start, end], sorted by time.
+// Must be called with bs.mu held.
+func (bs *BarStore) load(symbol string, interval Interval, start, end time.Time) ([]TimeSale, error) {
+	if IsDailyInterval(interval) {
+		tss, err := loadTimeSales(bs.path(symbol, interval))
+		if err != nil {
+			return nil, fmt.Errorf("error reading bars for %v (%v): %v", symbol, interval, err)
+		}
+		return tss, nil
+	}
+
+	files, err := ioutil.ReadDir(bs.dayDir(symbol, interval))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Files are listed in order of date.
+	var tss []TimeSale
+	for _, f := range files {
+		date := strings.TrimSuffix(f.Name(), ".json")
+		if f.IsDir() || date == f.Name() ||
+			(!start.IsZero() && date < marketDate(start)) ||
+			(!end.IsZero() && date > marketDate(end)) {
+			continue
+		}
+
+		day, err := loadTimeSales(bs.dayPath(symbol, interval, date))
+		if err != nil {
+			return nil, fmt.Errorf("error reading bars for %v (%v) on %v: %v", symbol, interval, date, err)
+		}
+		tss = append(tss, day...)
+	}
+	return tss, nil
+}
+
+// Read a file of time sales, returning nil if it does not exist.
+func loadTimeSales(path string) ([]TimeSale, error) {
+	var tss []TimeSale
+	err := readJSONFile(path, &tss)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return tss, err
+}
+
+func saveTimeSales(path string, tss []TimeSale) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeJSONFile(path, tss)
+}
+
+// How far back Tradier keeps intraday time sales.
+func historyRetention(interval Interval) time.Duration {
+	if interval == IntervalTick {
+		return 5 * 24 * time.Hour
+	}
+	return 20 * 24 * time.Hour
+}
+
+// Daily history is available from this date.
+var historyStart = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Sync downloads any data for the given symbols that is missing from the
+// store. Intraday data is fetched for each trading day within Tradier's
+// retention window that has not already been completely synced; days
+// in progress are refetched on the next Sync. Daily, weekly and monthly
+// bars are fetched from the last stored bar onwards.
+func (bs *BarStore) Sync(symbols []string, interval Interval) error {
+	if bs.client == nil {
+		return errors.New("bar store has no client to sync with")
+	}
+
+	for _, symbol := range symbols {
+		var err error
+		if IsDailyInterval(interval) {
+			err = bs.syncDaily(symbol, interval)
+		} else {
+			err = bs.syncIntraday(symbol, interval, time.Now())
+		}
+		if err != nil {
+			return fmt.Errorf("error syncing %v (%v): %v", symbol, interval, err)
+		}
+	}
+
+	return nil
+}
+
+func (bs *BarStore) syncDaily(symbol string, interval Interval) error {
+	bs.mu.Lock()
+	existing, err := bs.load(symbol, interval, time.Time{}, time.Time{})
+	bs.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// The last bar may have been incomplete, so fetch it again.
+	start := historyStart
+	if len(existing) > 0 {
+		start = existing[len(existing)-1].Date.Time
+	}
+	tss, err := bs.client.GetTimeSales(symbol, interval, start, time.Time{})
+	if err != nil {
+		return err
+	}
+	return bs.Put(symbol, interval, tss)
+}
+
+func (bs *BarStore) syncIntraday(symbol string, interval Interval, now time.Time) error {
+	days, err := bs.calendar.TradingDaysBetween(now.Add(-historyRetention(interval)), now)
+	if err != nil {
+		return err
+	}
+
+	bs.mu.Lock()
+	synced, err := bs.loadSynced(symbol, interval)
+	bs.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Group the missing days into consecutive runs of trading days,
+	// and fetch each run with a single request.
+	var run []TradingDay
+	for i, day := range days {
+		if !synced[day.Date.Format("2006-01-02")] {
+			run = append(run, day)
+		}
+		if len(run) > 0 && (synced[day.Date.Format("2006-01-02")] || i+1 == len(days)) {
+			if err := bs.syncDays(symbol, interval, run, now); err != nil {
+				return err
+			}
+			run = nil
+		}
+	}
+
+	return nil
+}
+
+// Fetch and store a consecutive run of trading days, then record
+// the days that have finished trading and were returned as synced.
+func (bs *BarStore) syncDays(symbol string, interval Interval, days []TradingDay, now time.Time) error {
+	first, last := days[0].Date, days[len(days)-1].Date
+	start := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, MarketLocation())
+	end := time.Date(last.Year(), last.Month(), last.Day(), 23, 59, 59, 0, MarketLocation())
+	if end.After(now) {
+		end = now
+	}
+
+	tss, err := bs.client.GetTimeSales(symbol, interval, start, end)
+	if err != nil {
+		return err
+	}
+	if err := bs.Put(symbol, interval, tss); err != nil {
+		return err
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	synced, err := bs.loadSynced(symbol, interval)
+	if err != nil {
+		return err
+	}
+	hasBars := make(map[string]bool)
+	for _, ts := range tss {
+		hasBars[marketDate(TimeSaleTime(ts))] = true
+	}
+	// Near the edge of the retention window Tradier may already have
+	// dropped part of the day, so it is only trusted if it has bars.
+	trustEmpty := now.Add(-historyRetention(interval) + 24*time.Hour)
+	for _, day := range days {
+		date := day.Date.Format("2006-01-02")
+		if !sessionEnd(day).Before(now) {
+			continue
+		}
+		// A day without bars (e.g. an illiquid symbol) is only marked
+		// synced if other days did return bars, so that a transient
+		// empty response is retried on the next Sync.
+		if hasBars[date] || (len(tss) > 0 && sessionStart(day).After(trustEmpty)) {
+			synced[date] = true
+		}
+	}
+	return bs.saveSynced(symbol, interval, synced)
+}
+
+// Must be called with bs.mu held.
+func (bs *BarStore) loadSynced(symbol string, interval Interval) (map[string]bool, error) {
+	var dates []string
+	err := readJSONFile(bs.syncedPath(symbol, interval), &dates)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	synced := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		synced[date] = true
+	}
+	return synced, nil
+}
+
+// Must be called with bs.mu held.
+func (bs *BarStore) saveSynced(symbol string, interval Interval, synced map[string]bool) error {
+	dates := make([]string, 0, len(synced))
+	for date := range synced {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	path := bs.syncedPath(symbol, interval)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeJSONFile(path, dates)
+}
@@ -0,0 +1,170 @@
+package tradier
+
+import (
+	"fmt"
+)
+
+// The side that closes a position opened with the given side.
+var closingSides = map[string]string{
+	Buy:        Sell,
+	SellShort:  BuyToCover,
+	BuyToOpen:  SellToClose,
+	SellToOpen: BuyToClose,
+}
+
+// NewBracketOrder returns an OTOCO order that opens a position with entry
+// and, once it fills, brackets it with a takeProfit limit order and a
+// stopLoss stop (or stop limit) order, one of which cancels the other.
+//
+// The exit legs must close the full entry quantity on the same security,
+// and their prices must be on the correct side of the entry price: for
+// a long entry the take profit must be above and the stop loss below.
+func NewBracketOrder(entry, takeProfit, stopLoss Order) (Order, error) {
+	closingSide, ok := closingSides[entry.Side]
+	if !ok {
+		return Order{}, fmt.Errorf("entry side must open a position, got %v", entry.Side)
+	}
+	if entry.Type != MarketOrder && entry.Type != LimitOrder &&
+		entry.Type != StopOrder && entry.Type != StopLimitOrder {
+		return Order{}, fmt.Errorf("unsupported entry order type: %v", entry.Type)
+	}
+	if takeProfit.Type != LimitOrder {
+		return Order{}, fmt.Errorf("take profit must be a limit order, got %v", takeProfit.Type)
+	}
+	if stopLoss.Type != StopOrder && stopLoss.Type != StopLimitOrder {
+		return Order{}, fmt.Errorf("stop loss must be a stop or stop limit order, got %v", stopLoss.Type)
+	}
+
+	for _, exit := range []Order{takeProfit, stopLoss} {
+		if err := checkSameSecurity(entry, exit); err != nil {
+			return Order{}, err
+		}
+		if exit.Side != closingSide {
+			return Order{}, fmt.Errorf("exit side must be %v to close %v entry, got %v",
+				closingSide, entry.Side, exit.Side)
+		}
+		if exit.Quantity != entry.Quantity {
+			return Order{}, fmt.Errorf("exit quantity %v does not match entry quantity %v",
+				exit.Quantity, entry.Quantity)
+		}
+	}
+
+	long := SideSign(entry.Side) > 0
+	if err := checkExitPrices(long, takeProfit.Price, stopLoss.StopPrice); err != nil {
+		return Order{}, err
+	}
+	if stopLoss.Type == StopLimitOrder && !priceAtOrBeyond(long, stopLoss.StopPrice, stopLoss.Price) {
+		return Order{}, fmt.Errorf("stop loss limit %.2f is on the wrong side of its stop %.2f",
+			stopLoss.Price, stopLoss.StopPrice)
+	}
+	if entryPrice := referencePrice(entry); entryPrice > 0 {
+		if err := checkExitPrices(long, takeProfit.Price, entryPrice); err != nil {
+			return Order{}, fmt.Errorf("take profit vs. entry: %v", err)
+		}
+		if err := checkExitPrices(long, entryPrice, stopLoss.StopPrice); err != nil {
+			return Order{}, fmt.Errorf("stop loss vs. entry: %v", err)
+		}
+	}
+
+	return Order{
+		Class:    OneTriggersOneCancelsOther,
+		Duration: entry.Duration,
+		Legs:     []Order{entry, takeProfit, stopLoss},
+	}, nil
+}
+
+// NewOCO returns an OCO order in which a fill of either leg cancels the other.
+// Both legs must be on the same side, security and quantity. If one leg
+// is a limit and the other a stop, the limit must be on the profitable side
+// of the stop, i.e. above it for sells.
+func NewOCO(a, b Order) (Order, error) {
+	if err := checkSameSecurity(a, b); err != nil {
+		return Order{}, err
+	}
+	if a.Side != b.Side {
+		return Order{}, fmt.Errorf("OCO legs must be on the same side, got %v and %v", a.Side, b.Side)
+	}
+	if a.Quantity != b.Quantity {
+		return Order{}, fmt.Errorf("OCO leg quantities %v and %v do not match", a.Quantity, b.Quantity)
+	}
+	if a.Duration != b.Duration {
+		return Order{}, fmt.Errorf("OCO leg durations %v and %v do not match", a.Duration, b.Duration)
+	}
+
+	limit, stop := a, b
+	if a.Type == StopOrder || a.Type == StopLimitOrder {
+		limit, stop = b, a
+	}
+	if limit.Type == LimitOrder && (stop.Type == StopOrder || stop.Type == StopLimitOrder) {
+		// Exiting a long position is a sell, so prices are ordered as for a long entry.
+		long := SideSign(a.Side) < 0
+		if err := checkExitPrices(long, limit.Price, stop.StopPrice); err != nil {
+			return Order{}, err
+		}
+	}
+
+	return Order{
+		Class:    OneCancelsOther,
+		Duration: a.Duration,
+		Legs:     []Order{a, b},
+	}, nil
+}
+
+func checkSameSecurity(a, b Order) error {
+	if a.Symbol != b.Symbol || a.OptionSymbol != b.OptionSymbol {
+		return fmt.Errorf("legs must be on the same security, got %v and %v", legSymbol(a), legSymbol(b))
+	}
+	return nil
+}
+
+// Check that the higher price is above the lower price, where for a short
+// position "higher" and "lower" are reversed.
+func checkExitPrices(long bool, higher, lower float64) error {
+	if higher <= 0 || lower <= 0 {
+		return fmt.Errorf("exit prices must be set")
+	}
+	if long && higher <= lower {
+		return fmt.Errorf("price %.2f must be above %.2f", higher, lower)
+	} else if !long && higher >= lower {
+		return fmt.Errorf("price %.2f must be below %.2f", higher, lower)
+	}
+	return nil
+}
+
+// Whether the limit price of an exit stop limit order is at or beyond its
+// stop, in the direction the position loses money.
+func priceAtOrBeyond(long bool, stop, limit float64) bool {
+	if long {
+		return limit <= stop
+	}
+	return limit >= stop
+}
+
+// The price at which an order is expected to fill, or 0 for market orders.
+func referencePrice(order Order) float64 {
+	switch order.Type {
+	case LimitOrder:
+		return order.Price
+	case StopOrder, StopLimitOrder:
+		return order.StopPrice
+	}
+	return 0
+}
+
+// BracketLegs splits an OTOCO order (e.g. from GetOrderStatus) into its
+// triggering entry order and the two exit orders that cancel each other.
+func BracketLegs(order *Order) (entry, takeProfit, stopLoss *Order, err error) {
+	if order.Class != OneTriggersOneCancelsOther {
+		return nil, nil, nil, fmt.Errorf("not an OTOCO order: %v", order.Class)
+	}
+	if len(order.Legs) != 3 {
+		return nil, nil, nil, fmt.Errorf("expected 3 legs in OTOCO order, got %d", len(order.Legs))
+	}
+
+	entry = &order.Legs[0]
+	takeProfit, stopLoss = &order.Legs[1], &order.Legs[2]
+	if takeProfit.Type == StopOrder || takeProfit.Type == StopLimitOrder {
+		takeProfit, stopLoss = stopLoss, takeProfit
+	}
+	return entry, takeProfit, stopLoss, nil
+}
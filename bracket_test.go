@@ -0,0 +1,69 @@
+package tradier
+
+import (
+	"testing"
+)
+
+func TestGetOrderStatusOTOCO(t *testing.T) {
+	client := newTestClient(t, serveFixture(t, "order_otoco.json"))
+	order, err := client.GetOrderStatus(229065)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Class != OneTriggersOneCancelsOther || order.NumLegs != 3 || len(order.Legs) != 3 {
+		t.Fatalf("expected OTOCO order with 3 legs, got %v with %d legs", order.Class, len(order.Legs))
+	}
+
+	entry, takeProfit, stopLoss, err := BracketLegs(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Id != 229066 || entry.Side != Buy || entry.Type != LimitOrder || entry.Status != Open {
+		t.Errorf("unexpected entry leg: %+v", entry)
+	}
+	assertFloat(t, "entry price", entry.Price, 500)
+	if takeProfit.Id != 229068 || takeProfit.Type != LimitOrder || takeProfit.Status != Pending {
+		t.Errorf("unexpected take profit leg: %+v", takeProfit)
+	}
+	assertFloat(t, "take profit price", takeProfit.Price, 520)
+	if stopLoss.Id != 229067 || stopLoss.Type != StopOrder || stopLoss.Duration != "gtc" {
+		t.Errorf("unexpected stop loss leg: %+v", stopLoss)
+	}
+	assertFloat(t, "stop price", stopLoss.StopPrice, 490)
+	if stopLoss.CreateDate.IsZero() {
+		t.Error("expected leg create date to be decoded")
+	}
+}
+
+func TestGetOrderStatusOCO(t *testing.T) {
+	client := newTestClient(t, serveFixture(t, "order_oco.json"))
+	order, err := client.GetOrderStatus(229070)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Class != OneCancelsOther || order.Status != Filled || len(order.Legs) != 2 {
+		t.Fatalf("expected filled OCO order with 2 legs, got %v %v with %d legs",
+			order.Status, order.Class, len(order.Legs))
+	}
+
+	expected := []struct {
+		id     int
+		typ    string
+		status string
+		filled float64
+	}{
+		{229071, LimitOrder, Filled, 10},
+		{229072, StopOrder, Canceled, 0},
+	}
+	for i, e := range expected {
+		leg := order.Legs[i]
+		if leg.Id != e.id || leg.Type != e.typ || leg.Status != e.status || leg.Symbol != "AAPL" {
+			t.Errorf("unexpected leg %d: %+v", i, leg)
+		}
+		assertFloat(t, "executed quantity", leg.ExecutedQuantity, e.filled)
+	}
+
+	if _, _, _, err := BracketLegs(order); err == nil {
+		t.Error("expected error splitting an OCO order into bracket legs")
+	}
+}
@@ -0,0 +1,19 @@
+package tradier
+
+// Broker is the set of account and order methods used by trading
+// strategies. It is implemented by *Client for live trading and
+// by *PaperBroker for simulated trading.
+type Broker interface {
+	PlaceOrder(order Order) (int, error)
+	ChangeOrder(orderId int, order Order) error
+	CancelOrder(orderId int) error
+	GetOpenOrders() ([]*Order, error)
+	GetOrderStatus(orderId int) (*Order, error)
+	GetAccountPositions() ([]*Position, error)
+	GetAccountBalances() (*AccountBalances, error)
+}
+
+var (
+	_ Broker = (*Client)(nil)
+	_ Broker = (*PaperBroker)(nil)
+)
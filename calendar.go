@@ -0,0 +1,264 @@
+package tradier
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Number of days NextOpen and PreviousClose search before giving up.
+const maxSessionSearchDays = 30
+
+// SessionHours is the start and end of a trading session, in the
+// market timezone. Both are zero if there is no session.
+type SessionHours struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (sh SessionHours) IsZero() bool {
+	return sh.Start.IsZero() && sh.End.IsZero()
+}
+
+// Contains returns whether t is within [Start, End).
+func (sh SessionHours) Contains(t time.Time) bool {
+	return !sh.IsZero() && !t.Before(sh.Start) && t.Before(sh.End)
+}
+
+func parseSessionHours(date time.Time, start, end string) SessionHours {
+	s, err := parseSessionTime(date, start)
+	if err != nil {
+		return SessionHours{}
+	}
+	e, err := parseSessionTime(date, end)
+	if err != nil {
+		return SessionHours{}
+	}
+	return SessionHours{s, e}
+}
+
+// TradingDay is a day of the market calendar with its session
+// times parsed in America/New_York.
+type TradingDay struct {
+	// Midnight at the start of the day, in the market timezone.
+	Date        time.Time
+	Open        bool
+	Description string
+	Premarket   SessionHours
+	Regular     SessionHours
+	Postmarket  SessionHours
+	// Whether the regular session ends before the usual 16:00 close.
+	EarlyClose bool
+}
+
+// ParseTradingDay parses the session times of a day of the market calendar.
+func ParseTradingDay(day MarketCalendar) TradingDay {
+	y, m, d := day.Date.Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, MarketLocation())
+	td := TradingDay{
+		Date:        date,
+		Open:        day.Status == MarketDayOpen,
+		Description: day.Description,
+	}
+	if !td.Open {
+		return td
+	}
+
+	td.Premarket = parseSessionHours(date, day.Premarket.Start, day.Premarket.End)
+	td.Regular = parseSessionHours(date, day.Open.Start, day.Open.End)
+	td.Postmarket = parseSessionHours(date, day.Postmarket.Start, day.Postmarket.End)
+	if !td.Regular.IsZero() {
+		td.EarlyClose = td.Regular.End.Before(time.Date(y, m, d, 16, 0, 0, 0, MarketLocation()))
+	}
+	return td
+}
+
+// Session is the state of the market at a point in time.
+type Session struct {
+	State MarketState
+	// Bounds of the current session; zero if the market is closed.
+	Hours SessionHours
+	// Whether the regular session closes early on this day.
+	EarlyClose bool
+}
+
+// SessionAt returns the session that t falls in on this day.
+func (td TradingDay) SessionAt(t time.Time) Session {
+	session := Session{State: MarketClosed, EarlyClose: td.EarlyClose}
+	sessions := []struct {
+		state MarketState
+		hours SessionHours
+	}{
+		{MarketPremarket, td.Premarket},
+		{MarketOpen, td.Regular},
+		{MarketPostmarket, td.Postmarket},
+	}
+	for _, s := range sessions {
+		if s.hours.Contains(t) {
+			session.State = s.state
+			session.Hours = s.hours
+			break
+		}
+	}
+	return session
+}
+
+// The start of extended hours trading on the given day.
+func sessionStart(day TradingDay) time.Time {
+	for _, sh := range []SessionHours{day.Premarket, day.Regular} {
+		if !sh.IsZero() {
+			return sh.Start
+		}
+	}
+	return day.Date
+}
+
+// The end of extended hours trading on the given day.
+func sessionEnd(day TradingDay) time.Time {
+	for _, sh := range []SessionHours{day.Postmarket, day.Regular} {
+		if !sh.IsZero() {
+			return sh.End
+		}
+	}
+	return day.Date.AddDate(0, 0, 1)
+}
+
+// TradingCalendar answers questions about market hours from the
+// market calendar, which is fetched from Tradier a month at a time
+// and cached.
+type TradingCalendar struct {
+	client *Client
+
+	mu     sync.Mutex
+	months map[string][]MarketCalendar
+}
+
+func NewTradingCalendar(client *Client) *TradingCalendar {
+	return &TradingCalendar{
+		client: client,
+		months: make(map[string][]MarketCalendar),
+	}
+}
+
+// Day returns the calendar for the day containing t in the market timezone.
+// Days missing from the calendar are returned as closed.
+func (cal *TradingCalendar) Day(t time.Time) (TradingDay, error) {
+	t = t.In(MarketLocation())
+	calendar, err := cal.month(t.Year(), t.Month())
+	if err != nil {
+		return TradingDay{}, err
+	}
+
+	date := t.Format("2006-01-02")
+	for _, day := range calendar {
+		if day.Date.Format("2006-01-02") == date {
+			return ParseTradingDay(day), nil
+		}
+	}
+	return TradingDay{Date: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, MarketLocation())}, nil
+}
+
+// IsTradingDay returns whether the market is open on the day containing t.
+func (cal *TradingCalendar) IsTradingDay(t time.Time) (bool, error) {
+	day, err := cal.Day(t)
+	return day.Open, err
+}
+
+// SessionAt returns the state of the market at t.
+func (cal *TradingCalendar) SessionAt(t time.Time) (Session, error) {
+	day, err := cal.Day(t)
+	if err != nil {
+		return Session{}, err
+	}
+	return day.SessionAt(t), nil
+}
+
+// NextOpen returns the start of the next regular session after t.
+func (cal *TradingCalendar) NextOpen(t time.Time) (time.Time, error) {
+	for i := 0; i < maxSessionSearchDays; i++ {
+		day, err := cal.Day(t.AddDate(0, 0, i))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if day.Open && !day.Regular.IsZero() && day.Regular.Start.After(t) {
+			return day.Regular.Start, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no market open within %d days after %v", maxSessionSearchDays, t)
+}
+
+// PreviousClose returns the end of the last regular session that
+// ended at or before t.
+func (cal *TradingCalendar) PreviousClose(t time.Time) (time.Time, error) {
+	for i := 0; i < maxSessionSearchDays; i++ {
+		day, err := cal.Day(t.AddDate(0, 0, -i))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if day.Open && !day.Regular.IsZero() && !day.Regular.End.After(t) {
+			return day.Regular.End, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no market close within %d days before %v", maxSessionSearchDays, t)
+}
+
+// ExpirationClose returns the end of the regular session on the
+// expiration date, which is before 4pm on early close days. Days the
+// calendar does not have hours for are assumed to close at 4pm.
+func (cal *TradingCalendar) ExpirationClose(expiration time.Time) (time.Time, error) {
+	y, m, d := expiration.Date()
+	day, err := cal.Day(time.Date(y, m, d, 12, 0, 0, 0, MarketLocation()))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if day.Open && !day.Regular.IsZero() {
+		return day.Regular.End, nil
+	}
+	return ExpirationClose(expiration), nil
+}
+
+// TradingDaysBetween returns the days the market is open between
+// start and end (inclusive).
+func (cal *TradingCalendar) TradingDaysBetween(start, end time.Time) ([]TradingDay, error) {
+	start = start.In(MarketLocation())
+	end = end.In(MarketLocation())
+	first := start.Format("2006-01-02")
+	last := end.Format("2006-01-02")
+
+	var days []TradingDay
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); !month.After(end); month = month.AddDate(0, 1, 0) {
+		calendar, err := cal.month(month.Year(), month.Month())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, day := range calendar {
+			td := ParseTradingDay(day)
+			date := td.Date.Format("2006-01-02")
+			if td.Open && date >= first && date <= last {
+				days = append(days, td)
+			}
+		}
+	}
+
+	return days, nil
+}
+
+func (cal *TradingCalendar) month(year int, month time.Month) ([]MarketCalendar, error) {
+	key := fmt.Sprintf("%04d-%02d", year, month)
+	cal.mu.Lock()
+	calendar, ok := cal.months[key]
+	cal.mu.Unlock()
+	if ok {
+		return calendar, nil
+	}
+
+	calendar, err := cal.client.GetMarketCalendar(year, month)
+	if err != nil {
+		return nil, err
+	}
+	cal.mu.Lock()
+	cal.months[key] = calendar
+	cal.mu.Unlock()
+	return calendar, nil
+}
@@ -0,0 +1,160 @@
+package tradier
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Return a calendar that serves testdata/calendar_<year>_<month>.json
+// for each month requested.
+func newTestCalendar(t *testing.T) *TradingCalendar {
+	return NewTradingCalendar(newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		name := fmt.Sprintf("calendar_%s_%s.json", q.Get("year"), q.Get("month"))
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})))
+}
+
+func marketTime(month time.Month, day, hour, minute int) time.Time {
+	return time.Date(2024, month, day, hour, minute, 0, 0, MarketLocation())
+}
+
+func TestExpirationCloseEarlyClose(t *testing.T) {
+	cal := newTestCalendar(t)
+	expiration := time.Date(2024, time.November, 29, 0, 0, 0, 0, time.UTC)
+	close, err := cal.ExpirationClose(expiration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := time.Date(2024, time.November, 29, 13, 0, 0, 0, MarketLocation())
+	if !close.Equal(expected) {
+		t.Errorf("expected close at %v, got %v", expected, close)
+	}
+
+	oc := NewOptionChain("XYZ", 100, []*Quote{{
+		Symbol:         "XYZ241129C00100000",
+		OptionType:     Call,
+		Strike:         100,
+		ExpirationDate: DateTime{expiration},
+	}})
+	now := time.Date(2024, time.November, 29, 9, 30, 0, 0, MarketLocation())
+	assertFloat(t, "YearsToExpiration before SetExpirationCloses",
+		oc.YearsToExpiration(expiration, now), 6.5/24/DaysPerYear)
+	if err := oc.SetExpirationCloses(cal); err != nil {
+		t.Fatal(err)
+	}
+	assertFloat(t, "YearsToExpiration", oc.YearsToExpiration(expiration, now), 3.5/24/DaysPerYear)
+}
+
+func TestNextOpen(t *testing.T) {
+	cal := newTestCalendar(t)
+	testCases := []struct {
+		name     string
+		t        time.Time
+		expected time.Time
+	}{
+		{"before the open", marketTime(time.November, 26, 8, 0), marketTime(time.November, 26, 9, 30)},
+		{"at the open", marketTime(time.November, 26, 9, 30), marketTime(time.November, 27, 9, 30)},
+		{"across Thanksgiving", marketTime(time.November, 27, 17, 0), marketTime(time.November, 29, 9, 30)},
+		{"on Thanksgiving", marketTime(time.November, 28, 10, 0), marketTime(time.November, 29, 9, 30)},
+		{"across the weekend and month", marketTime(time.November, 29, 14, 0), marketTime(time.December, 2, 9, 30)},
+	}
+
+	for _, tc := range testCases {
+		got, err := cal.NextOpen(tc.t)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.name, err)
+		}
+		if !got.Equal(tc.expected) {
+			t.Errorf("%v: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestPreviousClose(t *testing.T) {
+	cal := newTestCalendar(t)
+	testCases := []struct {
+		name     string
+		t        time.Time
+		expected time.Time
+	}{
+		{"after the close", marketTime(time.November, 26, 18, 0), marketTime(time.November, 26, 16, 0)},
+		{"at the close", marketTime(time.November, 26, 16, 0), marketTime(time.November, 26, 16, 0)},
+		{"on Thanksgiving", marketTime(time.November, 28, 12, 0), marketTime(time.November, 27, 16, 0)},
+		{"after an early close", marketTime(time.November, 29, 14, 0), marketTime(time.November, 29, 13, 0)},
+		{"across the weekend and month", marketTime(time.December, 2, 9, 0), marketTime(time.November, 29, 13, 0)},
+	}
+
+	for _, tc := range testCases {
+		got, err := cal.PreviousClose(tc.t)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.name, err)
+		}
+		if !got.Equal(tc.expected) {
+			t.Errorf("%v: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestCalendarSessionAt(t *testing.T) {
+	cal := newTestCalendar(t)
+	testCases := []struct {
+		name       string
+		t          time.Time
+		state      MarketState
+		end        time.Time
+		earlyClose bool
+	}{
+		{"overnight", marketTime(time.November, 27, 6, 0), MarketClosed, time.Time{}, false},
+		{"premarket", marketTime(time.November, 27, 8, 0), MarketPremarket, marketTime(time.November, 27, 9, 24), false},
+		{"between premarket and open", marketTime(time.November, 27, 9, 25), MarketClosed, time.Time{}, false},
+		{"regular", marketTime(time.November, 27, 10, 0), MarketOpen, marketTime(time.November, 27, 16, 0), false},
+		{"postmarket", marketTime(time.November, 27, 16, 0), MarketPostmarket, marketTime(time.November, 27, 20, 0), false},
+		{"holiday", marketTime(time.November, 28, 10, 0), MarketClosed, time.Time{}, false},
+		{"early close regular", marketTime(time.November, 29, 12, 59), MarketOpen, marketTime(time.November, 29, 13, 0), true},
+		{"early close postmarket", marketTime(time.November, 29, 13, 0), MarketPostmarket, marketTime(time.November, 29, 17, 0), true},
+		{"after early close postmarket", marketTime(time.November, 29, 17, 30), MarketClosed, time.Time{}, true},
+		{"weekend", marketTime(time.November, 30, 10, 0), MarketClosed, time.Time{}, false},
+	}
+
+	for _, tc := range testCases {
+		session, err := cal.SessionAt(tc.t)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.name, err)
+		}
+		if session.State != tc.state || !session.Hours.End.Equal(tc.end) || session.EarlyClose != tc.earlyClose {
+			t.Errorf("%v: expected %v until %v (early close: %v), got %+v",
+				tc.name, tc.state, tc.end, tc.earlyClose, session)
+		}
+	}
+}
+
+func TestTradingDaysBetween(t *testing.T) {
+	cal := newTestCalendar(t)
+	days, err := cal.TradingDaysBetween(marketTime(time.November, 27, 15, 0), marketTime(time.December, 2, 10, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"2024-11-27", "2024-11-29", "2024-12-02"}
+	var got []string
+	for _, day := range days {
+		got = append(got, day.Date.Format("2006-01-02"))
+	}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Fatalf("expected trading days %v, got %v", expected, got)
+	}
+	if !days[1].EarlyClose || days[0].EarlyClose || days[2].EarlyClose {
+		t.Errorf("expected only %v to close early", expected[1])
+	}
+}
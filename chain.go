@@ -0,0 +1,363 @@
+package tradier
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ChainQuery selects options from the chains of an underlying.
+// Zero values leave the corresponding criterion unrestricted.
+type ChainQuery struct {
+	// Range of days to expiration.
+	MinDTE int
+	MaxDTE int
+	// Put or Call.
+	OptionType string
+	// Range of strike / spot.
+	MinMoneyness float64
+	MaxMoneyness float64
+	// Range of the absolute value of delta.
+	MinDelta float64
+	MaxDelta float64
+	// Number of strikes to include above and below spot.
+	StrikesAroundSpot int
+	MinOpenInterest   float64
+	MinVolume         int
+	// Maximum bid-ask spread.
+	MaxSpread float64
+	// Maximum number of chains to fetch concurrently.
+	Concurrency int
+}
+
+// QueryOptionChain fetches the chains of the expirations selected
+// by the query concurrently, and returns the options that match it.
+func (tc *Client) QueryOptionChain(underlying string, query ChainQuery) (*OptionChain, error) {
+	quotes, err := tc.GetQuotes([]string{underlying})
+	if err != nil {
+		return nil, err
+	} else if len(quotes) == 0 {
+		return nil, fmt.Errorf("no quote for %v", underlying)
+	}
+	spot := QuoteMark(quotes[0])
+
+	allExpirations, err := tc.GetOptionExpirationDates(underlying)
+	if err != nil {
+		return nil, err
+	}
+	var expirations []time.Time
+	now := time.Now()
+	for _, exp := range allExpirations {
+		if query.matchesDTE(DaysToExpiration(exp, now)) {
+			expirations = append(expirations, exp)
+		}
+	}
+
+	chains := make([][]*Quote, len(expirations))
+	errs := make([]error, len(expirations))
+	parallelDo(len(expirations), query.Concurrency, func(i int) {
+		chains[i], errs[i] = tc.GetOptionChain(underlying, expirations[i])
+	})
+
+	var selected []*Quote
+	for i, chain := range chains {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("error fetching %v chain for %v: %v",
+				underlying, expirations[i].Format("2006-01-02"), errs[i])
+		}
+		selected = append(selected, query.Filter(chain, spot)...)
+	}
+
+	return NewOptionChain(underlying, spot, selected), nil
+}
+
+func (q ChainQuery) matchesDTE(dte int) bool {
+	return dte >= q.MinDTE && (q.MaxDTE == 0 || dte <= q.MaxDTE)
+}
+
+// DaysToExpiration returns the number of calendar days from now
+// (in the market timezone) until the expiration date.
+func DaysToExpiration(expiration, now time.Time) int {
+	y, m, d := now.In(MarketLocation()).Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	y, m, d = expiration.Date()
+	exp := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return int(math.Round(exp.Sub(today).Hours() / 24))
+}
+
+// DaysPerYear is the day count used to annualize time to expiration.
+const DaysPerYear = 365
+
+// ExpirationClose returns the usual 4pm close, in the market timezone,
+// on the expiration date. TradingCalendar.ExpirationClose accounts for
+// early closes.
+func ExpirationClose(expiration time.Time) time.Time {
+	y, m, d := expiration.Date()
+	return time.Date(y, m, d, 16, 0, 0, 0, MarketLocation())
+}
+
+// YearsToExpiration returns the time from now until the 4pm close
+// on the expiration date, in years.
+func YearsToExpiration(expiration, now time.Time) float64 {
+	return yearsUntil(ExpirationClose(expiration), now)
+}
+
+func yearsUntil(close, now time.Time) float64 {
+	return close.Sub(now).Hours() / 24 / DaysPerYear
+}
+
+// Filter returns the options in a single expiration's chain that match
+// the strike, type and liquidity criteria of the query.
+func (q ChainQuery) Filter(chain []*Quote, spot float64) []*Quote {
+	allowedStrikes := q.strikesAroundSpot(chain, spot)
+
+	var result []*Quote
+	for _, option := range chain {
+		if q.OptionType != "" && option.OptionType != q.OptionType {
+			continue
+		}
+		if allowedStrikes != nil && !allowedStrikes[option.Strike] {
+			continue
+		}
+		if spot > 0 {
+			moneyness := option.Strike / spot
+			if (q.MinMoneyness > 0 && moneyness < q.MinMoneyness) ||
+				(q.MaxMoneyness > 0 && moneyness > q.MaxMoneyness) {
+				continue
+			}
+		}
+		delta := math.Abs(option.Greeks.Delta)
+		if (q.MinDelta > 0 && delta < q.MinDelta) || (q.MaxDelta > 0 && delta > q.MaxDelta) {
+			continue
+		}
+		if option.OpenInterest < q.MinOpenInterest || option.Volume < q.MinVolume {
+			continue
+		}
+		if q.MaxSpread > 0 && (option.Bid <= 0 || option.Ask-option.Bid > q.MaxSpread) {
+			continue
+		}
+
+		result = append(result, option)
+	}
+
+	return result
+}
+
+// The set of strikes within StrikesAroundSpot of spot, or nil if unrestricted.
+func (q ChainQuery) strikesAroundSpot(chain []*Quote, spot float64) map[float64]bool {
+	if q.StrikesAroundSpot <= 0 {
+		return nil
+	}
+
+	strikes := uniqueStrikes(chain)
+	i := sort.SearchFloat64s(strikes, spot)
+	lo := i - q.StrikesAroundSpot
+	if lo < 0 {
+		lo = 0
+	}
+	hi := i + q.StrikesAroundSpot
+	if hi > len(strikes) {
+		hi = len(strikes)
+	}
+
+	allowed := make(map[float64]bool, hi-lo)
+	for _, strike := range strikes[lo:hi] {
+		allowed[strike] = true
+	}
+	return allowed
+}
+
+func uniqueStrikes(chain []*Quote) []float64 {
+	seen := make(map[float64]bool)
+	var strikes []float64
+	for _, option := range chain {
+		if !seen[option.Strike] {
+			seen[option.Strike] = true
+			strikes = append(strikes, option.Strike)
+		}
+	}
+	sort.Float64s(strikes)
+	return strikes
+}
+
+// OptionPair is the call and put at a strike. Either may be nil.
+type OptionPair struct {
+	Strike float64
+	Call   *Quote
+	Put    *Quote
+}
+
+// OptionChain is a set of options on an underlying,
+// indexed by expiration, strike and type.
+type OptionChain struct {
+	Underlying string
+	Spot       float64
+
+	expirations []time.Time
+	// Sorted by strike, by expiration date ("2006-01-02").
+	strikes map[string][]*OptionPair
+	// Close of each expiration that was looked up in the market calendar,
+	// by expiration date ("2006-01-02").
+	closes map[string]time.Time
+}
+
+func NewOptionChain(underlying string, spot float64, options []*Quote) *OptionChain {
+	oc := &OptionChain{
+		Underlying: underlying,
+		Spot:       spot,
+		strikes:    make(map[string][]*OptionPair),
+		closes:     make(map[string]time.Time),
+	}
+
+	pairs := make(map[string]map[float64]*OptionPair)
+	for _, option := range options {
+		exp := option.ExpirationDate.Format("2006-01-02")
+		if pairs[exp] == nil {
+			pairs[exp] = make(map[float64]*OptionPair)
+			oc.expirations = append(oc.expirations, option.ExpirationDate.Time)
+		}
+		pair := pairs[exp][option.Strike]
+		if pair == nil {
+			pair = &OptionPair{Strike: option.Strike}
+			pairs[exp][option.Strike] = pair
+			oc.strikes[exp] = append(oc.strikes[exp], pair)
+		}
+
+		if option.OptionType == Call {
+			pair.Call = option
+		} else if option.OptionType == Put {
+			pair.Put = option
+		}
+	}
+
+	sort.Slice(oc.expirations, func(i, j int) bool { return oc.expirations[i].Before(oc.expirations[j]) })
+	for _, strikes := range oc.strikes {
+		sort.Slice(strikes, func(i, j int) bool { return strikes[i].Strike < strikes[j].Strike })
+	}
+	return oc
+}
+
+// Expirations returns the expiration dates in the chain, in order.
+func (oc *OptionChain) Expirations() []time.Time {
+	return oc.expirations
+}
+
+// SetExpirationCloses looks up the close of each expiration in the
+// market calendar, so that time to expiration accounts for early closes.
+func (oc *OptionChain) SetExpirationCloses(cal *TradingCalendar) error {
+	for _, exp := range oc.expirations {
+		close, err := cal.ExpirationClose(exp)
+		if err != nil {
+			return err
+		}
+		oc.closes[exp.Format("2006-01-02")] = close
+	}
+	return nil
+}
+
+// YearsToExpiration returns the time from now until the close on the
+// expiration date, in years. The close is 4pm unless it was set from
+// the market calendar with SetExpirationCloses.
+func (oc *OptionChain) YearsToExpiration(expiration, now time.Time) float64 {
+	if close, ok := oc.closes[expiration.Format("2006-01-02")]; ok {
+		return yearsUntil(close, now)
+	}
+	return YearsToExpiration(expiration, now)
+}
+
+// NearestExpiration returns the expiration closest to the given number of
+// days to expiration, or the zero time if the chain is empty.
+func (oc *OptionChain) NearestExpiration(dte int, now time.Time) time.Time {
+	var best time.Time
+	bestDiff := math.MaxInt32
+	for _, exp := range oc.expirations {
+		diff := DaysToExpiration(exp, now) - dte
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff < bestDiff {
+			best, bestDiff = exp, diff
+		}
+	}
+	return best
+}
+
+// Strikes returns the call/put pairs of an expiration, sorted by strike.
+func (oc *OptionChain) Strikes(expiration time.Time) []*OptionPair {
+	return oc.strikes[expiration.Format("2006-01-02")]
+}
+
+// Quotes returns all options in the chain.
+func (oc *OptionChain) Quotes() []*Quote {
+	var result []*Quote
+	for _, exp := range oc.expirations {
+		for _, pair := range oc.Strikes(exp) {
+			if pair.Call != nil {
+				result = append(result, pair.Call)
+			}
+			if pair.Put != nil {
+				result = append(result, pair.Put)
+			}
+		}
+	}
+	return result
+}
+
+// Get returns the option with the given expiration, strike and type, or nil.
+func (oc *OptionChain) Get(expiration time.Time, strike float64, optionType string) *Quote {
+	strikes := oc.Strikes(expiration)
+	i := sort.Search(len(strikes), func(i int) bool { return strikes[i].Strike >= strike })
+	if i == len(strikes) || strikes[i].Strike != strike {
+		return nil
+	}
+	if optionType == Call {
+		return strikes[i].Call
+	}
+	return strikes[i].Put
+}
+
+// ATM returns the pair at the strike closest to spot, or nil
+// if there are no options with the expiration.
+func (oc *OptionChain) ATM(expiration time.Time) *OptionPair {
+	var best *OptionPair
+	for _, pair := range oc.Strikes(expiration) {
+		if best == nil || math.Abs(pair.Strike-oc.Spot) < math.Abs(best.Strike-oc.Spot) {
+			best = pair
+		}
+	}
+	return best
+}
+
+// ByDelta returns the option whose delta is closest to the given delta.
+// Positive deltas select calls and negative deltas select puts.
+func (oc *OptionChain) ByDelta(expiration time.Time, delta float64) *Quote {
+	var best *Quote
+	for _, pair := range oc.Strikes(expiration) {
+		option := pair.Call
+		if delta < 0 {
+			option = pair.Put
+		}
+		if option == nil || option.Greeks.Delta == 0 {
+			continue
+		}
+		if best == nil || math.Abs(option.Greeks.Delta-delta) < math.Abs(best.Greeks.Delta-delta) {
+			best = option
+		}
+	}
+	return best
+}
+
+// Straddle returns the at-the-money call and put. Use NewStraddle
+// to create the order.
+func (oc *OptionChain) Straddle(expiration time.Time) (call, put *Quote) {
+	for _, pair := range oc.Strikes(expiration) {
+		if pair.Call == nil || pair.Put == nil {
+			continue
+		}
+		if call == nil || math.Abs(pair.Strike-oc.Spot) < math.Abs(call.Strike-oc.Spot) {
+			call, put = pair.Call, pair.Put
+		}
+	}
+	return call, put
+}
@@ -0,0 +1,258 @@
+package tradier
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// PutCallRatio compares put and call activity.
+type PutCallRatio struct {
+	CallVolume        int     `json:"call_volume"`
+	PutVolume         int     `json:"put_volume"`
+	VolumeRatio       float64 `json:"volume_ratio"`
+	CallOpenInterest  float64 `json:"call_open_interest"`
+	PutOpenInterest   float64 `json:"put_open_interest"`
+	OpenInterestRatio float64 `json:"open_interest_ratio"`
+}
+
+func (pc *PutCallRatio) add(pair *OptionPair) {
+	if pair.Call != nil {
+		pc.CallVolume += pair.Call.Volume
+		pc.CallOpenInterest += pair.Call.OpenInterest
+	}
+	if pair.Put != nil {
+		pc.PutVolume += pair.Put.Volume
+		pc.PutOpenInterest += pair.Put.OpenInterest
+	}
+}
+
+func (pc *PutCallRatio) computeRatios() {
+	if pc.CallVolume > 0 {
+		pc.VolumeRatio = float64(pc.PutVolume) / float64(pc.CallVolume)
+	}
+	if pc.CallOpenInterest > 0 {
+		pc.OpenInterestRatio = pc.PutOpenInterest / pc.CallOpenInterest
+	}
+}
+
+// ExpectedMove is the move in the underlying implied by the price
+// of the at-the-money straddle.
+type ExpectedMove struct {
+	Strike   float64 `json:"strike"`
+	Straddle float64 `json:"straddle"`
+	Lower    float64 `json:"lower"`
+	Upper    float64 `json:"upper"`
+	// Straddle / spot.
+	Percent float64 `json:"percent"`
+}
+
+// GammaStrike is the dealer gamma exposure at a strike, in dollars of
+// delta per 1% move in the underlying. Dealers are assumed to be long
+// the calls and short the puts that customers hold.
+type GammaStrike struct {
+	Strike    float64 `json:"strike"`
+	CallGamma float64 `json:"call_gamma"`
+	PutGamma  float64 `json:"put_gamma"`
+	NetGamma  float64 `json:"net_gamma"`
+}
+
+type ExpirationAnalytics struct {
+	Expiration   time.Time     `json:"expiration"`
+	MaxPain      float64       `json:"max_pain"`
+	PutCall      PutCallRatio  `json:"put_call"`
+	ExpectedMove *ExpectedMove `json:"expected_move,omitempty"`
+}
+
+// ChainAnalytics summarizes the positioning in an option chain.
+type ChainAnalytics struct {
+	Underlying  string                `json:"underlying"`
+	Spot        float64               `json:"spot"`
+	Expirations []ExpirationAnalytics `json:"expirations"`
+	PutCall     PutCallRatio          `json:"put_call"`
+	// Summed over all expirations.
+	GammaByStrike []GammaStrike `json:"gamma_by_strike"`
+	NetGamma      float64       `json:"net_gamma"`
+	// The underlying price at which dealers' net gamma changes sign,
+	// or zero if it does not within 50% of spot.
+	ZeroGamma float64 `json:"zero_gamma"`
+}
+
+// Analytics computes max pain, put/call ratios and expected moves for
+// each expiration in the chain, and dealer gamma exposure across the chain.
+func (oc *OptionChain) Analytics(now time.Time) *ChainAnalytics {
+	ca := &ChainAnalytics{
+		Underlying: oc.Underlying,
+		Spot:       oc.Spot,
+	}
+
+	for _, exp := range oc.expirations {
+		ea := ExpirationAnalytics{
+			Expiration:   exp,
+			MaxPain:      oc.MaxPain(exp),
+			ExpectedMove: oc.ExpectedMove(exp),
+		}
+		for _, pair := range oc.Strikes(exp) {
+			ea.PutCall.add(pair)
+			ca.PutCall.add(pair)
+		}
+		ea.PutCall.computeRatios()
+		ca.Expirations = append(ca.Expirations, ea)
+	}
+	ca.PutCall.computeRatios()
+
+	ca.GammaByStrike = oc.GammaExposure()
+	for _, g := range ca.GammaByStrike {
+		ca.NetGamma += g.NetGamma
+	}
+	ca.ZeroGamma = oc.ZeroGamma(now)
+	return ca
+}
+
+func contractSize(q *Quote) float64 {
+	if q.ContractSize > 0 {
+		return float64(q.ContractSize)
+	}
+	return defaultContractSize
+}
+
+// MaxPain returns the strike at which the options of the expiration
+// would expire with the least total value.
+func (oc *OptionChain) MaxPain(expiration time.Time) float64 {
+	strikes := oc.Strikes(expiration)
+	var maxPain float64
+	minPayout := math.Inf(1)
+	for _, settle := range strikes {
+		var payout float64
+		for _, pair := range strikes {
+			if pair.Call != nil && settle.Strike > pair.Strike {
+				payout += (settle.Strike - pair.Strike) * pair.Call.OpenInterest * contractSize(pair.Call)
+			}
+			if pair.Put != nil && settle.Strike < pair.Strike {
+				payout += (pair.Strike - settle.Strike) * pair.Put.OpenInterest * contractSize(pair.Put)
+			}
+		}
+		if payout < minPayout {
+			minPayout, maxPain = payout, settle.Strike
+		}
+	}
+	return maxPain
+}
+
+// ExpectedMove returns the move implied by the at-the-money straddle,
+// or nil if there is no straddle with a price.
+func (oc *OptionChain) ExpectedMove(expiration time.Time) *ExpectedMove {
+	call, put := oc.Straddle(expiration)
+	if call == nil || put == nil {
+		return nil
+	}
+	straddle := QuoteMark(call) + QuoteMark(put)
+	if straddle <= 0 || oc.Spot <= 0 {
+		return nil
+	}
+
+	return &ExpectedMove{
+		Strike:   call.Strike,
+		Straddle: straddle,
+		Lower:    oc.Spot - straddle,
+		Upper:    oc.Spot + straddle,
+		Percent:  straddle / oc.Spot,
+	}
+}
+
+// GammaExposure returns dealer gamma exposure by strike, summed over all
+// expirations, from the greeks and open interest in the chain.
+func (oc *OptionChain) GammaExposure() []GammaStrike {
+	// Dollar delta change for a 1% move, per unit of gamma.
+	scale := oc.Spot * oc.Spot * 0.01
+
+	byStrike := make(map[float64]*GammaStrike)
+	for _, exp := range oc.expirations {
+		for _, pair := range oc.Strikes(exp) {
+			g, ok := byStrike[pair.Strike]
+			if !ok {
+				g = &GammaStrike{Strike: pair.Strike}
+				byStrike[pair.Strike] = g
+			}
+			if pair.Call != nil {
+				g.CallGamma += pair.Call.Greeks.Gamma * pair.Call.OpenInterest * contractSize(pair.Call) * scale
+			}
+			if pair.Put != nil {
+				g.PutGamma -= pair.Put.Greeks.Gamma * pair.Put.OpenInterest * contractSize(pair.Put) * scale
+			}
+		}
+	}
+
+	result := make([]GammaStrike, 0, len(byStrike))
+	for _, g := range byStrike {
+		g.NetGamma = g.CallGamma + g.PutGamma
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Strike < result[j].Strike })
+	return result
+}
+
+// ZeroGamma returns the underlying price, within 50% of spot, at which
+// dealers' net gamma exposure changes sign, or zero if it does not.
+// Gamma at other prices is recomputed with Black-Scholes from each
+// option's mid IV, up to the expiration closes set with
+// SetExpirationCloses (4pm otherwise).
+func (oc *OptionChain) ZeroGamma(now time.Time) float64 {
+	if oc.Spot <= 0 {
+		return 0
+	}
+
+	const steps = 200
+	lo, hi := 0.5*oc.Spot, 1.5*oc.Spot
+	prevLevel, prevGamma := 0.0, 0.0
+	var zeros []float64
+	for i := 0; i <= steps; i++ {
+		level := lo + (hi-lo)*float64(i)/steps
+		gamma := oc.netGammaAt(level, now)
+		if i > 0 && (prevGamma < 0) != (gamma < 0) && gamma != prevGamma {
+			zeros = append(zeros, prevLevel+(level-prevLevel)*prevGamma/(prevGamma-gamma))
+		}
+		prevLevel, prevGamma = level, gamma
+	}
+
+	// Return the crossing closest to spot.
+	var best float64
+	for _, z := range zeros {
+		if best == 0 || math.Abs(z-oc.Spot) < math.Abs(best-oc.Spot) {
+			best = z
+		}
+	}
+	return best
+}
+
+// Dealers' net gamma exposure if the underlying were at the given price.
+func (oc *OptionChain) netGammaAt(spot float64, now time.Time) float64 {
+	var net float64
+	for _, exp := range oc.expirations {
+		t := oc.YearsToExpiration(exp, now)
+		if t <= 0 {
+			continue
+		}
+
+		for _, pair := range oc.Strikes(exp) {
+			if pair.Call != nil {
+				net += BlackScholesGamma(spot, pair.Strike, t, 0, 0, pair.Call.Greeks.MidIV) * pair.Call.OpenInterest * contractSize(pair.Call)
+			}
+			if pair.Put != nil {
+				net -= BlackScholesGamma(spot, pair.Strike, t, 0, 0, pair.Put.Greeks.MidIV) * pair.Put.OpenInterest * contractSize(pair.Put)
+			}
+		}
+	}
+	return net * spot * spot * 0.01
+}
+
+// BlackScholesGamma returns the Black-Scholes-Merton gamma of an option
+// expiring in t years on an underlying with a continuous dividend yield.
+func BlackScholesGamma(spot, strike, t, rate, dividendYield, vol float64) float64 {
+	if vol <= 0 || t <= 0 {
+		return 0
+	}
+	volT := vol * math.Sqrt(t)
+	d1 := (math.Log(spot/strike) + (rate-dividendYield+0.5*vol*vol)*t) / volT
+	return math.Exp(-dividendYield*t) * math.Exp(-0.5*d1*d1) / math.Sqrt(2*math.Pi) / (spot * volT)
+}
@@ -0,0 +1,115 @@
+package tradier
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var (
+	testAnalyticsNow        = time.Date(2024, 6, 3, 10, 0, 0, 0, MarketLocation())
+	testAnalyticsExpiration = time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)
+)
+
+func analyticsOption(optionType string, strike, openInterest float64, volume int, bid, ask, gamma float64) *Quote {
+	return &Quote{
+		OptionType:     optionType,
+		Strike:         strike,
+		ExpirationDate: DateTime{testAnalyticsExpiration},
+		OpenInterest:   openInterest,
+		Volume:         volume,
+		Bid:            bid,
+		Ask:            ask,
+		Greeks:         Greeks{Gamma: gamma, MidIV: 0.2},
+	}
+}
+
+// A chain with put open interest below spot, call open interest above
+// it, and an at-the-money straddle whose gamma cancels.
+func testAnalyticsChain() *OptionChain {
+	return NewOptionChain("XYZ", 100, []*Quote{
+		analyticsOption(Put, 90, 1000, 600, 0.9, 1.1, 0.03),
+		analyticsOption(Call, 100, 200, 100, 3.9, 4.1, 0.05),
+		analyticsOption(Put, 100, 200, 100, 3.4, 3.6, 0.05),
+		analyticsOption(Call, 110, 1000, 300, 0.9, 1.1, 0.03),
+	})
+}
+
+func TestMaxPain(t *testing.T) {
+	// Payout at each settlement price, per share of open interest:
+	//   90: the 100 put pays 10 * 200 = 2000
+	//  100: nothing
+	//  110: the 100 call pays 10 * 200 = 2000
+	oc := testAnalyticsChain()
+	assertFloat(t, "max pain", oc.MaxPain(testAnalyticsExpiration), 100)
+
+	// Adding in the money calls at 90 moves max pain down to them.
+	oc = NewOptionChain("XYZ", 100, append(oc.Quotes(), analyticsOption(Call, 90, 500, 0, 10, 11, 0)))
+	assertFloat(t, "max pain with 90 calls", oc.MaxPain(testAnalyticsExpiration), 90)
+}
+
+func TestExpectedMove(t *testing.T) {
+	em := testAnalyticsChain().ExpectedMove(testAnalyticsExpiration)
+	if em == nil {
+		t.Fatal("expected a straddle")
+	}
+	assertFloat(t, "strike", em.Strike, 100)
+	assertFloat(t, "straddle", em.Straddle, 7.5)
+	assertFloat(t, "lower", em.Lower, 92.5)
+	assertFloat(t, "upper", em.Upper, 107.5)
+	assertFloat(t, "percent", em.Percent, 0.075)
+}
+
+func TestGammaExposure(t *testing.T) {
+	// Gamma * open interest * 100 shares * (100^2 * 1%).
+	expected := []GammaStrike{
+		{Strike: 90, PutGamma: -300000, NetGamma: -300000},
+		{Strike: 100, CallGamma: 100000, PutGamma: -100000, NetGamma: 0},
+		{Strike: 110, CallGamma: 300000, NetGamma: 300000},
+	}
+	got := testAnalyticsChain().GammaExposure()
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d strikes, got %v", len(expected), got)
+	}
+	for i, g := range got {
+		assertFloat(t, "strike", g.Strike, expected[i].Strike)
+		assertFloat(t, "call gamma", g.CallGamma, expected[i].CallGamma)
+		assertFloat(t, "put gamma", g.PutGamma, expected[i].PutGamma)
+		assertFloat(t, "net gamma", g.NetGamma, expected[i].NetGamma)
+	}
+}
+
+func TestZeroGamma(t *testing.T) {
+	oc := testAnalyticsChain()
+	// Dealers are short gamma from the puts below spot and long gamma from
+	// the calls above it. With equal open interest and vol the gammas of
+	// the 90 put and 110 call are equal where d1(90) = -d1(110), i.e. at
+	// S = sqrt(90 * 110) * exp(-vol^2 t / 2).
+	years := oc.YearsToExpiration(testAnalyticsExpiration, testAnalyticsNow)
+	expected := math.Sqrt(90*110) * math.Exp(-0.2*0.2*years/2)
+
+	zero := oc.ZeroGamma(testAnalyticsNow)
+	if math.Abs(zero-expected) > 0.01 {
+		t.Errorf("expected zero gamma at %v, got %v", expected, zero)
+	}
+	if below := oc.netGammaAt(zero-1, testAnalyticsNow); below >= 0 {
+		t.Errorf("expected negative net gamma below the crossing, got %v", below)
+	}
+	if above := oc.netGammaAt(zero+1, testAnalyticsNow); above <= 0 {
+		t.Errorf("expected positive net gamma above the crossing, got %v", above)
+	}
+}
+
+func TestAnalytics(t *testing.T) {
+	ca := testAnalyticsChain().Analytics(testAnalyticsNow)
+	if len(ca.Expirations) != 1 {
+		t.Fatalf("expected one expiration, got %v", ca.Expirations)
+	}
+	assertFloat(t, "max pain", ca.Expirations[0].MaxPain, 100)
+	assertFloat(t, "put/call volume", ca.PutCall.VolumeRatio, 700.0/400)
+	assertFloat(t, "put/call open interest", ca.PutCall.OpenInterestRatio, 1)
+	assertFloat(t, "net gamma", ca.NetGamma, 0)
+	if ca.ZeroGamma <= 90 || ca.ZeroGamma >= 110 {
+		t.Errorf("expected zero gamma between 90 and 110, got %v", ca.ZeroGamma)
+	}
+}
@@ -0,0 +1,36 @@
+package tradier
+
+import (
+	"net/http"
+	"testing"
+)
+
+// Tradier returns single objects rather than lists for a one-symbol
+// quote request, an underlying with one expiration and a one-contract chain.
+func TestQueryOptionChainSingleObjects(t *testing.T) {
+	responses := map[string]string{
+		"/v1/markets/quotes":              `{"quotes":{"quote":{"symbol":"XYZ","type":"stock","last":10.0,"bid":9.9,"ask":10.1}}}`,
+		"/v1/markets/options/expirations": `{"expirations":{"date":"2099-01-16"}}`,
+		"/v1/markets/options/chains": `{"options":{"option":{"symbol":"XYZ990116C00010000","type":"option",` +
+			`"underlying":"XYZ","strike":10.0,"option_type":"call","expiration_date":"2099-01-16","bid":1.0,"ask":1.2}}}`,
+	}
+	tc := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+
+	oc, err := tc.QueryOptionChain("XYZ", ChainQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFloat(t, "Spot", oc.Spot, 10.0)
+	quotes := oc.Quotes()
+	if len(quotes) != 1 || quotes[0].Symbol != "XYZ990116C00010000" {
+		t.Fatalf("expected the single contract in the chain, got %v", quotes)
+	}
+}
@@ -0,0 +1,307 @@
+package tradier
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Starting points for an OrderChaser.
+const (
+	ChaseFromBid = "bid"
+	ChaseFromMid = "mid"
+	ChaseFromAsk = "ask"
+)
+
+// Reasons an OrderChaser stopped.
+const (
+	ChaseFilled   = "filled"
+	ChaseTimeout  = "timeout"
+	ChaseCanceled = "canceled"
+	ChaseRejected = "rejected"
+)
+
+// orderManager is the subset of Client methods used to work an order.
+type orderManager interface {
+	PlaceOrder(order Order) (int, error)
+	ChangeOrder(orderId int, order Order) error
+	CancelOrder(orderId int) error
+	GetOrderStatus(orderId int) (*Order, error)
+}
+
+// ChaseParams configures an OrderChaser.
+type ChaseParams struct {
+	// Where to place the initial limit price: ChaseFromBid, ChaseFromMid or ChaseFromAsk.
+	Start string
+	// Amount to move the limit price towards the far side of the market on
+	// each replace. If zero or less than one tick, the price moves by one tick.
+	Step float64
+	// Time to wait between replaces.
+	Interval time.Duration
+	// Worst price we are willing to pay (for buys) or receive (for sells).
+	LimitPrice float64
+	// Give up and cancel the order if it has not filled after this long.
+	Timeout time.Duration
+	// Whether the option trades in penny increments (the penny pilot program).
+	// Ignored for equities, which always trade in pennies.
+	PennyPilot bool
+}
+
+// ChaseResult summarizes the execution of an OrderChaser.
+type ChaseResult struct {
+	OrderId        int
+	Reason         string
+	FilledQuantity float64
+	FillPrice      float64
+	ArrivalMid     float64
+	// Difference between the fill price and the arrival mid, per share or
+	// contract. Positive values are worse than the arrival mid.
+	Slippage float64
+	Replaces int
+}
+
+// OrderChaser works a single-leg limit order, repeatedly re-pricing it
+// towards the far side of the market until it fills, reaches the worst
+// acceptable price and times out, or is canceled.
+//
+// Quotes for the order's symbol must be fed to the chaser with HandleQuote,
+// e.g. from a StreamDemuxer.
+type OrderChaser struct {
+	client orderManager
+	order  Order
+	params ChaseParams
+
+	mu        sync.Mutex
+	quote     *QuoteEvent
+	quoteChan chan struct{}
+	closeChan chan struct{}
+	closeOnce sync.Once
+}
+
+func NewOrderChaser(client orderManager, order Order, params ChaseParams) (*OrderChaser, error) {
+	if order.Class != Equity && order.Class != Option {
+		return nil, fmt.Errorf("can only chase equity or option orders, got %v", order.Class)
+	}
+	if SideSign(order.Side) == 0 {
+		return nil, fmt.Errorf("unknown order side: %v", order.Side)
+	}
+	if params.LimitPrice <= 0 {
+		return nil, fmt.Errorf("chasing requires a worst-price limit")
+	}
+	if params.Interval <= 0 {
+		return nil, fmt.Errorf("chasing requires a re-pricing interval")
+	}
+
+	order.Type = LimitOrder
+	if order.Duration == "" {
+		order.Duration = Day
+	}
+
+	return &OrderChaser{
+		client:    client,
+		order:     order,
+		params:    params,
+		quoteChan: make(chan struct{}, 1),
+		closeChan: make(chan struct{}),
+	}, nil
+}
+
+// HandleQuote updates the market used to price the order.
+// Quotes for other symbols are ignored.
+func (oc *OrderChaser) HandleQuote(q *QuoteEvent) {
+	if q.Symbol != legSymbol(oc.order) || q.Bid <= 0 || q.Ask <= 0 {
+		return
+	}
+
+	oc.mu.Lock()
+	oc.quote = q
+	oc.mu.Unlock()
+
+	select {
+	case oc.quoteChan <- struct{}{}:
+	default:
+	}
+}
+
+// Cancel stops chasing and cancels the working order.
+func (oc *OrderChaser) Cancel() {
+	oc.closeOnce.Do(func() { close(oc.closeChan) })
+}
+
+// Run places the order and works it until it is done. It blocks until
+// the order fills, times out or is canceled.
+func (oc *OrderChaser) Run() (*ChaseResult, error) {
+	result := &ChaseResult{}
+	var deadline <-chan time.Time
+	if oc.params.Timeout > 0 {
+		timer := time.NewTimer(oc.params.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	// Wait for the arrival quote.
+	select {
+	case <-oc.quoteChan:
+	case <-deadline:
+		result.Reason = ChaseTimeout
+		return result, nil
+	case <-oc.closeChan:
+		result.Reason = ChaseCanceled
+		return result, nil
+	}
+
+	q := oc.currentQuote()
+	result.ArrivalMid = (q.Bid + q.Ask) / 2
+	price := oc.initialPrice(q)
+
+	order := oc.order
+	order.Price = price
+	orderId, err := oc.client.PlaceOrder(order)
+	if err != nil {
+		return result, err
+	}
+	result.OrderId = orderId
+
+	ticker := time.NewTicker(oc.params.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			status, err := oc.client.GetOrderStatus(orderId)
+			if err != nil {
+				Logger.Printf("error getting status of chased order %v: %v\n", orderId, err)
+				continue
+			}
+			if done := oc.finished(status, result); done {
+				return result, nil
+			}
+
+			next := oc.nextPrice(price, oc.currentQuote())
+			if next != price {
+				order.Price = next
+				if err := oc.client.ChangeOrder(orderId, order); err != nil {
+					Logger.Printf("error re-pricing chased order %v: %v\n", orderId, err)
+					continue
+				}
+				price = next
+				result.Replaces++
+			}
+		case <-deadline:
+			return result, oc.stop(orderId, ChaseTimeout, result)
+		case <-oc.closeChan:
+			return result, oc.stop(orderId, ChaseCanceled, result)
+		}
+	}
+}
+
+func (oc *OrderChaser) currentQuote() *QuoteEvent {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	return oc.quote
+}
+
+// Cancel the working order and record its final state.
+func (oc *OrderChaser) stop(orderId int, reason string, result *ChaseResult) error {
+	cancelErr := oc.client.CancelOrder(orderId)
+	status, err := oc.client.GetOrderStatus(orderId)
+	if err == nil && oc.finished(status, result) && result.Reason == ChaseFilled {
+		// The order filled before we could cancel it.
+		return nil
+	}
+	result.Reason = reason
+	return cancelErr
+}
+
+// Record the order's fills in result, and return whether the order is done.
+func (oc *OrderChaser) finished(status *Order, result *ChaseResult) bool {
+	result.FilledQuantity = status.ExecutedQuantity
+	result.FillPrice = status.AverageFillPrice
+	if result.FilledQuantity > 0 {
+		result.Slippage = SideSign(oc.order.Side) * (result.FillPrice - result.ArrivalMid)
+	}
+
+	switch status.Status {
+	case Filled:
+		result.Reason = ChaseFilled
+		return true
+	case Canceled, Expired:
+		result.Reason = ChaseCanceled
+		return true
+	case Rejected:
+		result.Reason = ChaseRejected
+		return true
+	}
+	return false
+}
+
+func (oc *OrderChaser) initialPrice(q *QuoteEvent) float64 {
+	var price float64
+	switch oc.params.Start {
+	case ChaseFromBid:
+		price = q.Bid
+	case ChaseFromAsk:
+		price = q.Ask
+	default:
+		price = (q.Bid + q.Ask) / 2
+	}
+	return oc.clamp(price)
+}
+
+// Move the price one step towards the far side of the market,
+// but not beyond it or the worst-price limit. Steps are at least one
+// tick, since rounding to a valid tick would otherwise undo them.
+func (oc *OrderChaser) nextPrice(price float64, q *QuoteEvent) float64 {
+	step := math.Max(oc.params.Step, oc.tickSize(price))
+
+	buy := SideSign(oc.order.Side) > 0
+	if buy {
+		price = math.Min(price+step, q.Ask)
+	} else {
+		price = math.Max(price-step, q.Bid)
+	}
+	return oc.clamp(price)
+}
+
+// Limit the price to the worst acceptable price, and round it to a valid
+// tick in the passive direction.
+func (oc *OrderChaser) clamp(price float64) float64 {
+	buy := SideSign(oc.order.Side) > 0
+	if buy {
+		price = math.Min(price, oc.params.LimitPrice)
+	} else {
+		price = math.Max(price, oc.params.LimitPrice)
+	}
+
+	tick := oc.tickSize(price)
+	// Allow for floating point error so that exact multiples stay put.
+	ticks := price / tick
+	if buy {
+		price = math.Floor(ticks+1e-9) * tick
+	} else {
+		price = math.Ceil(ticks-1e-9) * tick
+	}
+	return math.Round(price*100) / 100
+}
+
+func (oc *OrderChaser) tickSize(price float64) float64 {
+	if oc.order.Class == Equity {
+		return 0.01
+	}
+	return OptionTickSize(price, oc.params.PennyPilot)
+}
+
+// OptionTickSize returns the minimum price increment for an option
+// trading at the given price.
+func OptionTickSize(price float64, pennyPilot bool) float64 {
+	if pennyPilot {
+		if price < 3 {
+			return 0.01
+		}
+		return 0.05
+	}
+	if price < 3 {
+		return 0.05
+	}
+	return 0.10
+}
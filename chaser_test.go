@@ -0,0 +1,49 @@
+package tradier
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrderChaserNextPrice(t *testing.T) {
+	testCases := []struct {
+		name     string
+		class    string
+		side     string
+		step     float64
+		price    float64
+		bid, ask float64
+		expected float64
+	}{
+		{"option buy by one tick", Option, BuyToOpen, 0, 1.00, 0.90, 1.20, 1.05},
+		{"option buy with sub-tick step", Option, BuyToOpen, 0.01, 1.00, 0.90, 1.20, 1.05},
+		{"option sell with sub-tick step", Option, SellToOpen, 0.01, 1.10, 0.90, 1.20, 1.05},
+		{"option buy with multi-tick step", Option, BuyToOpen, 0.10, 1.00, 0.90, 1.20, 1.10},
+		{"option buy stops at ask", Option, BuyToOpen, 0.50, 1.00, 0.90, 1.20, 1.20},
+		{"option buy stops at limit", Option, BuyToOpen, 0.50, 1.00, 0.90, 1.60, 1.25},
+		{"equity buy", Equity, Buy, 0.03, 10.00, 9.90, 10.20, 10.03},
+		{"equity sell", Equity, Sell, 0, 10.10, 9.90, 10.20, 10.09},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limit := 1.25
+			if tc.class == Equity {
+				limit = 10.15
+			}
+			if SideSign(tc.side) < 0 {
+				limit = 0.01
+			}
+			oc, err := NewOrderChaser(nil, Order{Class: tc.class, Side: tc.side}, ChaseParams{
+				Step:       tc.step,
+				Interval:   time.Second,
+				LimitPrice: limit,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := oc.nextPrice(tc.price, &QuoteEvent{Bid: tc.bid, Ask: tc.ask})
+			assertFloat(t, "next price", got, tc.expected)
+		})
+	}
+}
@@ -31,6 +31,10 @@ var (
 	// ErrNoAccountSelected is returned if account-specific methods
 	// are attempted to be used without selecting an account first.
 	ErrNoAccountSelected = errors.New("no account selected")
+
+	// ErrDryRun is returned by order methods of a client in dry run
+	// mode, after the order has been validated but not sent.
+	ErrDryRun = errors.New("dry run: order not sent")
 )
 
 type ClientParams struct {
@@ -40,6 +44,11 @@ type ClientParams struct {
 	Backoff    backoff.BackOff
 	RetryLimit int
 	Account    string
+	// If set, orders are never sent to Tradier: PlaceOrder previews the
+	// order, and ChangeOrder and CancelOrder only validate their arguments.
+	// All three then return ErrDryRun, so that callers cannot mistake
+	// a dry run for a live order.
+	DryRun bool
 }
 
 // DefaultParams returns ClientParams initialized with default values.
@@ -60,6 +69,7 @@ type Client struct {
 	authHeader string
 	backoff    backoff.BackOff
 	retryLimit int
+	dryRun     bool
 
 	account string
 }
@@ -71,6 +81,7 @@ func NewClient(params ClientParams) *Client {
 		authHeader: fmt.Sprintf("Bearer %s", params.AuthToken),
 		backoff:    params.Backoff,
 		retryLimit: params.RetryLimit,
+		dryRun:     params.DryRun,
 		account:    params.Account,
 	}
 }
@@ -101,7 +112,7 @@ func (tc *Client) GetAccountPositions() ([]*Position, error) {
 	url := tc.endpoint + "/v1/accounts/" + tc.account + "/positions"
 	var result struct {
 		Positions struct {
-			Position []*Position
+			Position positionList
 		}
 	}
 	err := tc.getJSON(url, &result)
@@ -170,6 +181,16 @@ func (tc *Client) PlaceOrder(order Order) (int, error) {
 		return 0, ErrNoAccountSelected
 	}
 
+	if tc.dryRun {
+		preview, err := tc.PreviewOrder(order)
+		if err == nil {
+			Logger.Printf("dry run: previewed %v order for %v: cost $%.2f, commission $%.2f, fees $%.2f\n",
+				order.Class, legSymbol(order), preview.Cost, preview.Commission, preview.Fees)
+			err = ErrDryRun
+		}
+		return 0, err
+	}
+
 	url := tc.endpoint + "/v1/accounts/" + tc.account + "/orders"
 	form, err := orderToParams(order)
 	if err != nil {
@@ -202,6 +223,8 @@ func (tc *Client) PlaceOrder(order Order) (int, error) {
 	return result.Order.Id, err
 }
 
+// Preview the given order without placing it. If Tradier rejects the
+// order, the returned error is a *PreviewError listing the reasons.
 func (tc *Client) PreviewOrder(order Order) (*OrderPreview, error) {
 	if tc.account == "" {
 		return nil, ErrNoAccountSelected
@@ -216,27 +239,56 @@ func (tc *Client) PreviewOrder(order Order) (*OrderPreview, error) {
 	form.Add("preview", "true")
 	resp, err := tc.do("POST", url, form, tc.retryLimit)
 	if err != nil {
+		if te, ok := err.(TradierError); ok {
+			if previewErr := decodePreviewErrors([]byte(te.Body)); previewErr != nil {
+				return nil, previewErr
+			}
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
 	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
 		return nil, errors.New(resp.Status + ": " + string(body))
 	}
+	return decodeOrderPreview(body)
+}
+
+func decodeOrderPreview(body []byte) (*OrderPreview, error) {
+	if previewErr := decodePreviewErrors(body); previewErr != nil {
+		return nil, previewErr
+	}
 
 	var result struct {
 		Order *OrderPreview
 	}
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(&result)
-	if err != nil {
-		return result.Order, err
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
 	} else if result.Order == nil {
-		err = fmt.Errorf("didn't receive order preview")
-	} else if result.Order.Status != StatusOK {
-		err = fmt.Errorf("received order status: %v", result.Order.Status)
+		return nil, fmt.Errorf("didn't receive order preview")
+	} else if result.Order.Status != StatusOK || len(result.Order.Errors) > 0 {
+		return result.Order, &PreviewError{
+			Status: result.Order.Status,
+			Errors: result.Order.Errors,
+		}
 	}
-	return result.Order, err
+	return result.Order, nil
+}
+
+// Tradier reports rejected previews as a top-level list of errors.
+func decodePreviewErrors(body []byte) *PreviewError {
+	var result struct {
+		Errors struct {
+			Error stringList
+		}
+	}
+	if err := json.Unmarshal(body, &result); err != nil || len(result.Errors.Error) == 0 {
+		return nil
+	}
+	return &PreviewError{Errors: result.Errors.Error}
 }
 
 // Convert the given order to URL parameters for a create order request.
@@ -249,6 +301,9 @@ func orderToParams(order Order) (url.Values, error) {
 	switch order.Class {
 	case Equity, Option:
 		form.Add("symbol", order.Symbol)
+		if order.Class == Option {
+			form.Add("option_symbol", order.OptionSymbol)
+		}
 		form.Add("side", order.Side)
 		form.Add("quantity", strconv.FormatFloat(order.Quantity, 'f', 0, 64))
 		form.Add("type", order.Type)
@@ -259,9 +314,14 @@ func orderToParams(order Order) (url.Values, error) {
 			form.Add("stop", strconv.FormatFloat(order.StopPrice, 'f', 2, 64))
 		}
 	case Multileg, Combo:
+		if err := validateMultilegOrder(order); err != nil {
+			return form, err
+		}
+
 		form.Add("symbol", order.Symbol)
 		form.Add("type", order.Type)
-		if order.Type == LimitOrder || order.Type == StopLimitOrder {
+		switch order.Type {
+		case LimitOrder, StopLimitOrder, Debit, Credit:
 			form.Add("price", strconv.FormatFloat(order.Price, 'f', 2, 64))
 		}
 		if order.Type == StopOrder || order.Type == StopLimitOrder {
@@ -269,9 +329,13 @@ func orderToParams(order Order) (url.Values, error) {
 		}
 
 		for i, leg := range order.Legs {
-			form.Add(fmt.Sprintf("option_symbol[%d]", i), leg.OptionSymbol)
+			// Equity legs of combo orders are identified by the
+			// absence of an option symbol.
+			if leg.OptionSymbol != "" {
+				form.Add(fmt.Sprintf("option_symbol[%d]", i), leg.OptionSymbol)
+			}
 			form.Add(fmt.Sprintf("side[%d]", i), leg.Side)
-			form.Add(fmt.Sprintf("quantity[%dd]", i), strconv.FormatFloat(leg.Quantity, 'f', 0, 64))
+			form.Add(fmt.Sprintf("quantity[%d]", i), strconv.FormatFloat(leg.Quantity, 'f', 0, 64))
 		}
 	case OneTriggersOther, OneCancelsOther, OneTriggersOneCancelsOther:
 		for i, leg := range order.Legs {
@@ -300,6 +364,14 @@ func (tc *Client) ChangeOrder(orderId int, order Order) error {
 		return ErrNoAccountSelected
 	}
 
+	if tc.dryRun {
+		if _, err := updateOrderParams(order); err != nil {
+			return err
+		}
+		Logger.Printf("dry run: not changing order %v\n", orderId)
+		return ErrDryRun
+	}
+
 	url := tc.endpoint + "/v1/accounts/" + tc.account + "/orders/" + strconv.Itoa(orderId)
 	form, err := updateOrderParams(order)
 	if err != nil {
@@ -363,6 +435,11 @@ func (tc *Client) CancelOrder(orderId int) error {
 		return ErrNoAccountSelected
 	}
 
+	if tc.dryRun {
+		Logger.Printf("dry run: not canceling order %v\n", orderId)
+		return ErrDryRun
+	}
+
 	url := tc.endpoint + "/v1/accounts/" + tc.account + "/orders/" + strconv.Itoa(orderId)
 	resp, err := tc.do("DELETE", url, nil, tc.retryLimit)
 	if err != nil {
@@ -443,7 +520,7 @@ func (tc *Client) GetOptionExpirationDates(symbol string) ([]time.Time, error) {
 	url := tc.endpoint + "/v1/markets/options/expirations" + params
 	var result struct {
 		Expirations struct {
-			Date []DateTime
+			Date dateTimeList
 		}
 	}
 	err := tc.getJSON(url, &result)
@@ -462,20 +539,48 @@ func (tc *Client) GetOptionStrikes(symbol string, expiration time.Time) ([]float
 	url := tc.endpoint + "/v1/markets/options/strikes" + params
 	var result struct {
 		Strikes struct {
-			Strike []float64
+			Strike floatList
 		}
 	}
 	err := tc.getJSON(url, &result)
 	return result.Strikes.Strike, err
 }
 
+// Get an option's expirations, with the type, contract size and strikes of each.
+// If includeAllRoots is true, expirations of all option roots of the
+// underlying are included (e.g. SPXW as well as SPX).
+func (tc *Client) GetOptionExpirations(symbol string, includeAllRoots bool) ([]Expiration, error) {
+	params := fmt.Sprintf("?symbol=%s&includeAllRoots=%t&strikes=true&contractSize=true&expirationType=true",
+		symbol, includeAllRoots)
+	url := tc.endpoint + "/v1/markets/options/expirations" + params
+	var result struct {
+		Expirations struct {
+			Expiration expirationList
+		}
+	}
+	err := tc.getJSON(url, &result)
+	return result.Expirations.Expiration, err
+}
+
+// Look up the option symbols of an underlying, grouped by option root.
+// https://documentation.tradier.com/brokerage-api/markets/get-lookup-options-symbols
+func (tc *Client) LookupOptionSymbols(underlying string) ([]OptionRoot, error) {
+	params := "?underlying=" + underlying
+	url := tc.endpoint + "/v1/markets/options/lookup" + params
+	var result struct {
+		Symbols optionRootList
+	}
+	err := tc.getJSON(url, &result)
+	return result.Symbols, err
+}
+
 // Get an option chain.
 func (tc *Client) GetOptionChain(symbol string, expiration time.Time) ([]*Quote, error) {
 	params := "?greeks=true&symbol=" + symbol + "&expiration=" + expiration.Format("2006-01-02")
 	url := tc.endpoint + "/v1/markets/options/chains" + params
 	var result struct {
 		Options struct {
-			Option []*Quote
+			Option quoteList
 		}
 	}
 	err := tc.getJSON(url, &result)
@@ -486,16 +591,12 @@ func (tc *Client) getTimeSalesUrl(symbol string, interval Interval, start, end t
 	url := tc.endpoint
 	timeFormat := "2006-01-02T15:04:05"
 	tz := time.UTC
-	var err error
 	if interval == IntervalDaily || interval == IntervalWeekly || interval == IntervalMonthly {
 		url = url + "/v1/markets/history"
 		timeFormat = "2006-01-02"
 	} else {
 		url = url + "/v1/markets/timesales"
-		tz, err = time.LoadLocation("America/New_York")
-		if err != nil {
-			panic(err)
-		}
+		tz = MarketLocation()
 	}
 	url = url + "?symbol=" + symbol
 	if interval != "" {
@@ -573,7 +674,7 @@ func bisect(start, end time.Time) time.Time {
 // Return daily, minute, or tick price bars for the given symbol.
 // Tick data is available for the past 5 days, minute data for the past 20 days,
 // and daily data since 1980-01-01.
-// NOTE: The results are split, but not dividend-adjusted.
+// NOTE: The results are split, but not dividend-adjusted. See GetAdjustedHistory.
 // https://developer.tradier.com/documentation/markets/get-history
 // https://developer.tradier.com/documentation/markets/get-timesales
 func (tc *Client) GetTimeSales(
@@ -788,14 +889,18 @@ func (tc *Client) getJSON(url string, result interface{}) error {
 }
 
 func (tc *Client) GetQuotes(symbols []string) ([]*Quote, error) {
+	return tc.getQuotes(symbols, true)
+}
+
+func (tc *Client) getQuotes(symbols []string, greeks bool) ([]*Quote, error) {
 	var result struct {
 		Quotes struct {
-			Quote []*Quote
+			Quote quoteList
 		}
 	}
 
 	uri := tc.endpoint + "/v1/markets/quotes"
-	data := url.Values{"symbols": {strings.Join(symbols, ",")}, "greeks": {"true"}}
+	data := url.Values{"symbols": {strings.Join(symbols, ",")}, "greeks": {strconv.FormatBool(greeks)}}
 
 	err := tc.postJSON(uri, data, &result)
 	if err != nil {
@@ -847,6 +952,7 @@ func (tc *Client) do(method, url string, body url.Values, maxRetries int) (*http
 			resp.Body.Close()
 			tradierErr := TradierError{
 				HttpStatusCode: resp.StatusCode,
+				Body:           string(respBody),
 			}
 			if jsonErr := json.Unmarshal(respBody, &tradierErr); jsonErr == nil {
 				// We extracted an error message, don't retry.
@@ -892,3 +998,114 @@ func (tc *Client) makeSignedRequest(method, url string, body url.Values) (*http.
 
 	return req, nil
 }
+
+// NOTE: Tradier returns a single object rather than a list
+// if there is only one expiration.
+type expirationList []Expiration
+
+func (el *expirationList) UnmarshalJSON(data []byte) error {
+	expirations := make([]Expiration, 0)
+	if err := json.Unmarshal(data, &expirations); err == nil {
+		*el = expirations
+		return nil
+	}
+
+	e := Expiration{}
+	err := json.Unmarshal(data, &e)
+	if err == nil {
+		*el = []Expiration{e}
+	}
+	return err
+}
+
+type optionRootList []OptionRoot
+
+func (orl *optionRootList) UnmarshalJSON(data []byte) error {
+	roots := make([]OptionRoot, 0)
+	if err := json.Unmarshal(data, &roots); err == nil {
+		*orl = roots
+		return nil
+	}
+
+	r := OptionRoot{}
+	err := json.Unmarshal(data, &r)
+	if err == nil {
+		*orl = []OptionRoot{r}
+	}
+	return err
+}
+
+// Tradier sends a single number rather than a list if there is only one element.
+type floatList []float64
+
+func (fl *floatList) UnmarshalJSON(data []byte) error {
+	floats := make([]float64, 0)
+	if err := json.Unmarshal(data, &floats); err == nil {
+		*fl = floats
+		return nil
+	}
+
+	var f float64
+	err := json.Unmarshal(data, &f)
+	if err == nil {
+		*fl = []float64{f}
+	}
+	return err
+}
+
+// Tradier sends a single date rather than a list if there is only one element.
+type dateTimeList []DateTime
+
+func (dl *dateTimeList) UnmarshalJSON(data []byte) error {
+	dates := make([]DateTime, 0)
+	if err := json.Unmarshal(data, &dates); err == nil {
+		*dl = dates
+		return nil
+	}
+
+	var d DateTime
+	err := json.Unmarshal(data, &d)
+	if err == nil {
+		*dl = []DateTime{d}
+	}
+	return err
+}
+
+// NOTE: Tradier returns a single object rather than a list
+// if quotes are requested for only one symbol, or if an option chain
+// has only one contract.
+type quoteList []*Quote
+
+func (ql *quoteList) UnmarshalJSON(data []byte) error {
+	quotes := make([]*Quote, 0)
+	if err := json.Unmarshal(data, &quotes); err == nil {
+		*ql = quotes
+		return nil
+	}
+
+	q := &Quote{}
+	err := json.Unmarshal(data, q)
+	if err == nil {
+		*ql = []*Quote{q}
+	}
+	return err
+}
+
+// NOTE: Tradier returns a single object rather than a list
+// if the account has only one position.
+type positionList []*Position
+
+func (pl *positionList) UnmarshalJSON(data []byte) error {
+	positions := make([]*Position, 0)
+	if err := json.Unmarshal(data, &positions); err == nil {
+		*pl = positions
+		return nil
+	}
+
+	p := &Position{}
+	err := json.Unmarshal(data, p)
+	if err == nil {
+		*pl = []*Position{p}
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package tradier
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/cenkalti/backoff"
+)
+
+// Return a client for the account "VA00000000" whose requests are
+// served by handler.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(ClientParams{
+		Endpoint: server.URL,
+		Client:   server.Client(),
+		Backoff:  &backoff.ZeroBackOff{},
+		Account:  "VA00000000",
+	})
+}
+
+// Return a handler that responds to every request with the given file in testdata.
+func serveFixture(t *testing.T, name string) http.Handler {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
@@ -0,0 +1,479 @@
+package tradier
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OrderSubmitter places and modifies orders. It is implemented by *Client
+// and *OrderGuard, so that conditional orders may be routed through
+// pre-trade risk checks.
+type OrderSubmitter interface {
+	PlaceOrder(order Order) (int, error)
+	ChangeOrder(orderId int, order Order) error
+}
+
+// Kinds of conditional orders.
+const (
+	TrailingStop  = "trailing_stop"
+	IfTouched     = "if_touched"
+	TimeTriggered = "time_triggered"
+)
+
+// Conditional order statuses.
+const (
+	ConditionPending   = "pending"
+	ConditionTriggered = "triggered"
+	ConditionCanceled  = "canceled"
+	ConditionFailed    = "failed"
+	// The order was being submitted when the engine stopped, so its
+	// state at the broker is unknown.
+	conditionSubmitting = "submitting"
+)
+
+// Trigger directions for if-touched orders.
+const (
+	TouchAbove = "above"
+	TouchBelow = "below"
+)
+
+// ConditionalOrder is an order held locally by a ConditionalOrderEngine
+// until its trigger condition is met.
+type ConditionalOrder struct {
+	Id     int
+	Kind   string
+	Symbol string // The symbol whose price is watched.
+	// The order that is placed when the condition triggers.
+	// For trailing stops, its side determines the direction of the trail.
+	Order Order
+
+	// Trailing stops trail the best price seen by TrailAmount dollars
+	// or TrailPercent percent.
+	TrailAmount  float64
+	TrailPercent float64
+	// If set, the trailing stop re-prices this resting stop order with
+	// ChangeOrder instead of placing Order when triggered. The resting
+	// order is only changed when the stop moves by at least one tick,
+	// and the conditional order is marked triggered when the price hits
+	// the stop, since the resting order will then be executed.
+	RestingOrderId int
+	BestPrice      float64
+	StopPrice      float64
+	// The stop price last sent to the resting order.
+	RestingStopPrice float64
+
+	// If-touched orders trigger when the price touches TriggerPrice
+	// from the given direction.
+	TriggerPrice float64
+	Direction    string
+
+	// Time-triggered orders trigger at TriggerAt.
+	TriggerAt time.Time
+
+	Status      string
+	CreatedAt   time.Time
+	TriggeredAt time.Time
+	OrderId     int // Id of the order placed when triggered.
+	Error       string
+}
+
+// ConditionalOrderEngine implements trailing stop, if-touched and
+// time-triggered orders client-side, since Tradier does not support them.
+// Prices are fed to the engine from the market stream, e.g. with
+// engine.Demuxer(), and triggered orders are submitted through the
+// given OrderSubmitter.
+//
+// If a state path is given, the engine persists its orders to that
+// file after every change and recovers them when it is recreated.
+type ConditionalOrderEngine struct {
+	submitter OrderSubmitter
+	statePath string
+
+	// If set, called with any errors placing or changing orders.
+	Errors func(err error)
+
+	mu     sync.Mutex
+	nextId int
+	orders map[int]*ConditionalOrder
+
+	closeChan chan struct{}
+	closeOnce sync.Once
+}
+
+func NewConditionalOrderEngine(submitter OrderSubmitter, statePath string) (*ConditionalOrderEngine, error) {
+	engine := &ConditionalOrderEngine{
+		submitter: submitter,
+		statePath: statePath,
+		nextId:    1,
+		orders:    make(map[int]*ConditionalOrder),
+		closeChan: make(chan struct{}),
+	}
+
+	if statePath != "" {
+		if err := engine.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return engine, nil
+}
+
+// Add a conditional order to the engine and return its id.
+func (e *ConditionalOrderEngine) Add(co ConditionalOrder) (int, error) {
+	if err := validateConditionalOrder(&co); err != nil {
+		return 0, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	co.Id = e.nextId
+	e.nextId++
+	co.Status = ConditionPending
+	co.CreatedAt = time.Now()
+	e.orders[co.Id] = &co
+	return co.Id, e.save()
+}
+
+func validateConditionalOrder(co *ConditionalOrder) error {
+	if co.Symbol == "" && co.Kind != TimeTriggered {
+		co.Symbol = legSymbol(co.Order)
+	}
+
+	switch co.Kind {
+	case TrailingStop:
+		if (co.TrailAmount <= 0) == (co.TrailPercent <= 0) {
+			return fmt.Errorf("trailing stop requires exactly one of TrailAmount or TrailPercent")
+		}
+		if SideSign(co.Order.Side) == 0 {
+			return fmt.Errorf("unknown order side: %v", co.Order.Side)
+		}
+	case IfTouched:
+		if co.TriggerPrice <= 0 {
+			return fmt.Errorf("if-touched order requires a trigger price")
+		}
+		if co.Direction != TouchAbove && co.Direction != TouchBelow {
+			return fmt.Errorf("unknown trigger direction: %v", co.Direction)
+		}
+	case TimeTriggered:
+		if co.TriggerAt.IsZero() {
+			return fmt.Errorf("time-triggered order requires a trigger time")
+		}
+	default:
+		return fmt.Errorf("unknown conditional order kind: %v", co.Kind)
+	}
+	return nil
+}
+
+// Cancel a pending conditional order. Resting orders at the broker
+// are not canceled.
+func (e *ConditionalOrderEngine) Cancel(id int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	co, ok := e.orders[id]
+	if !ok {
+		return fmt.Errorf("unknown conditional order: %v", id)
+	} else if co.Status != ConditionPending {
+		return fmt.Errorf("conditional order %v is %v", id, co.Status)
+	}
+	co.Status = ConditionCanceled
+	return e.save()
+}
+
+// Orders returns a copy of all conditional orders, sorted by id.
+func (e *ConditionalOrderEngine) Orders() []ConditionalOrder {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	result := make([]ConditionalOrder, 0, len(e.orders))
+	for _, co := range e.orders {
+		result = append(result, *co)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Id < result[j].Id })
+	return result
+}
+
+// Symbols returns the symbols watched by pending orders,
+// i.e. the symbols that should be streamed to the engine.
+func (e *ConditionalOrderEngine) Symbols() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	seen := make(map[string]bool)
+	var result []string
+	for _, co := range e.orders {
+		if co.Status == ConditionPending && co.Symbol != "" && !seen[co.Symbol] {
+			seen[co.Symbol] = true
+			result = append(result, co.Symbol)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Demuxer returns a StreamDemuxer that feeds quotes and trades to the engine.
+func (e *ConditionalOrderEngine) Demuxer() *StreamDemuxer {
+	return &StreamDemuxer{
+		Quotes: e.HandleQuote,
+		Trades: e.HandleTrade,
+		Errors: e.reportError,
+	}
+}
+
+// HandleQuote updates orders on the quoted symbol. Sell orders are
+// evaluated against the bid and buy orders against the ask.
+func (e *ConditionalOrderEngine) HandleQuote(q *QuoteEvent) {
+	e.handlePrice(q.Symbol, func(side string) float64 {
+		if SideSign(side) < 0 {
+			return q.Bid
+		}
+		return q.Ask
+	})
+}
+
+// HandleTrade updates orders on the traded symbol at the trade price.
+func (e *ConditionalOrderEngine) HandleTrade(t *TradeEvent) {
+	price := t.Price
+	if price == 0 {
+		price = t.Last
+	}
+	e.handlePrice(t.Symbol, func(side string) float64 { return price })
+}
+
+// Start checks time-triggered orders every interval until Stop is called.
+func (e *ConditionalOrderEngine) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				e.HandleTime(now)
+			case <-e.closeChan:
+				return
+			}
+		}
+	}()
+}
+
+func (e *ConditionalOrderEngine) Stop() {
+	e.closeOnce.Do(func() { close(e.closeChan) })
+}
+
+// HandleTime triggers any time-triggered orders due at or before now.
+func (e *ConditionalOrderEngine) HandleTime(now time.Time) {
+	e.mu.Lock()
+	var triggered []*ConditionalOrder
+	for _, co := range e.orders {
+		if co.Status == ConditionPending && co.Kind == TimeTriggered && !now.Before(co.TriggerAt) {
+			co.Status = conditionSubmitting
+			triggered = append(triggered, co)
+		}
+	}
+	if len(triggered) > 0 {
+		e.saveOrReport()
+	}
+	e.mu.Unlock()
+
+	e.submit(triggered)
+}
+
+type stopChange struct {
+	orderId int
+	order   Order
+}
+
+func (e *ConditionalOrderEngine) handlePrice(symbol string, priceFor func(side string) float64) {
+	e.mu.Lock()
+	var triggered []*ConditionalOrder
+	var changes []stopChange
+	changed := false
+	for _, co := range e.orders {
+		if co.Status != ConditionPending || co.Symbol != symbol {
+			continue
+		}
+		price := priceFor(co.Order.Side)
+		if price <= 0 {
+			continue
+		}
+
+		switch co.Kind {
+		case TrailingStop:
+			moved, hit := co.updateTrail(price)
+			changed = changed || moved
+			if co.RestingOrderId != 0 {
+				if hit {
+					co.Status = ConditionTriggered
+					co.TriggeredAt = time.Now()
+					co.OrderId = co.RestingOrderId
+					changed = true
+				} else if moved && math.Abs(co.StopPrice-co.RestingStopPrice) >= co.tickSize()-1e-9 {
+					co.RestingStopPrice = co.StopPrice
+					stop := co.Order
+					stop.Type = StopOrder
+					stop.StopPrice = co.StopPrice
+					changes = append(changes, stopChange{co.RestingOrderId, stop})
+				}
+			} else if hit {
+				co.Status = conditionSubmitting
+				triggered = append(triggered, co)
+			}
+		case IfTouched:
+			if (co.Direction == TouchAbove && price >= co.TriggerPrice) ||
+				(co.Direction == TouchBelow && price <= co.TriggerPrice) {
+				co.Status = conditionSubmitting
+				triggered = append(triggered, co)
+			}
+		}
+	}
+	if changed || len(triggered) > 0 {
+		e.saveOrReport()
+	}
+	e.mu.Unlock()
+
+	for _, c := range changes {
+		if err := e.submitter.ChangeOrder(c.orderId, c.order); err != nil {
+			e.reportError(fmt.Errorf("error re-pricing stop order %v: %v", c.orderId, err))
+		}
+	}
+	e.submit(triggered)
+}
+
+// Update the trailing stop with a new price. Returns whether the stop
+// moved, and whether the price has hit the stop.
+func (co *ConditionalOrder) updateTrail(price float64) (moved, hit bool) {
+	sell := SideSign(co.Order.Side) < 0
+	if co.BestPrice == 0 || (sell && price > co.BestPrice) || (!sell && price < co.BestPrice) {
+		co.BestPrice = price
+	}
+
+	trail := co.TrailAmount
+	if co.TrailPercent > 0 {
+		trail = co.BestPrice * co.TrailPercent / 100
+	}
+	stop := co.BestPrice + trail
+	if sell {
+		stop = co.BestPrice - trail
+	}
+	stop = roundPrice(stop, 0.01)
+
+	if co.StopPrice == 0 || (sell && stop > co.StopPrice) || (!sell && stop < co.StopPrice) {
+		co.StopPrice = stop
+		moved = true
+	}
+
+	hit = (sell && price <= co.StopPrice) || (!sell && price >= co.StopPrice)
+	return moved, hit
+}
+
+// The minimum increment of the stop price of the resting order.
+func (co *ConditionalOrder) tickSize() float64 {
+	if co.Order.OptionSymbol != "" {
+		return OptionTickSize(co.StopPrice, false)
+	}
+	return 0.01
+}
+
+// Place the orders for the given triggered conditional orders.
+func (e *ConditionalOrderEngine) submit(triggered []*ConditionalOrder) {
+	for _, co := range triggered {
+		e.mu.Lock()
+		order := co.Order
+		e.mu.Unlock()
+
+		orderId, err := e.submitter.PlaceOrder(order)
+
+		e.mu.Lock()
+		co.TriggeredAt = time.Now()
+		co.OrderId = orderId
+		if err != nil {
+			co.Status = ConditionFailed
+			co.Error = err.Error()
+		} else {
+			co.Status = ConditionTriggered
+		}
+		e.saveOrReport()
+		e.mu.Unlock()
+
+		if err != nil {
+			e.reportError(fmt.Errorf("error placing conditional order %v: %v", co.Id, err))
+		}
+	}
+}
+
+func (e *ConditionalOrderEngine) reportError(err error) {
+	Logger.Println(err)
+	if e.Errors != nil {
+		e.Errors(err)
+	}
+}
+
+// Must be called with e.mu held.
+func (e *ConditionalOrderEngine) saveOrReport() {
+	if err := e.save(); err != nil {
+		e.reportError(err)
+	}
+}
+
+// Persist the engine state. Must be called with e.mu held.
+func (e *ConditionalOrderEngine) save() error {
+	if e.statePath == "" {
+		return nil
+	}
+
+	orders := make([]*ConditionalOrder, 0, len(e.orders))
+	for _, co := range e.orders {
+		orders = append(orders, co)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].Id < orders[j].Id })
+	return writeJSONFile(e.statePath, orders)
+}
+
+func (e *ConditionalOrderEngine) load() error {
+	var orders []*ConditionalOrder
+	if err := readJSONFile(e.statePath, &orders); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, co := range orders {
+		if co.Status == conditionSubmitting {
+			// We don't know whether the order reached the broker, so
+			// don't risk submitting it twice.
+			co.Status = ConditionFailed
+			co.Error = "engine stopped while order was being submitted; check open orders"
+		}
+		e.orders[co.Id] = co
+		if co.Id >= e.nextId {
+			e.nextId = co.Id + 1
+		}
+	}
+	return nil
+}
+
+// Atomically write v as JSON to the given path.
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func readJSONFile(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
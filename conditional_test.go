@@ -0,0 +1,118 @@
+package tradier
+
+import (
+	"testing"
+	"time"
+)
+
+type recordingSubmitter struct {
+	placed  []Order
+	changed []Order
+}
+
+func (rs *recordingSubmitter) PlaceOrder(order Order) (int, error) {
+	rs.placed = append(rs.placed, order)
+	return 100 + len(rs.placed), nil
+}
+
+func (rs *recordingSubmitter) ChangeOrder(orderId int, order Order) error {
+	rs.changed = append(rs.changed, order)
+	return nil
+}
+
+func TestConditionalOrderRestingTrailingStop(t *testing.T) {
+	submitter := &recordingSubmitter{}
+	engine, err := NewConditionalOrderEngine(submitter, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := engine.Add(ConditionalOrder{
+		Kind:           TrailingStop,
+		Order:          stockOrder("AAPL", Sell, StopOrder, 10, 0),
+		TrailAmount:    1,
+		RestingOrderId: 42,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trade := func(price float64) {
+		engine.HandleTrade(&TradeEvent{Symbol: "AAPL", Price: price})
+	}
+	trade(100)     // Stop at 99.
+	trade(100.004) // Stop unchanged after rounding.
+	trade(100.5)   // Stop at 99.50.
+	trade(100.5)
+	trade(100.2)
+	trade(99.5) // Stop hit.
+	trade(105)  // Ignored: the resting order has been executed.
+
+	var stops []float64
+	for _, o := range submitter.changed {
+		stops = append(stops, o.StopPrice)
+	}
+	expected := []float64{99, 99.5}
+	if len(stops) != len(expected) {
+		t.Fatalf("expected stop changes %v, got %v", expected, stops)
+	}
+	for i := range expected {
+		assertFloat(t, "stop", stops[i], expected[i])
+	}
+	if len(submitter.placed) != 0 {
+		t.Errorf("expected no orders to be placed, got %v", submitter.placed)
+	}
+
+	co := engine.Orders()[0]
+	if co.Id != id || co.Status != ConditionTriggered || co.OrderId != 42 {
+		t.Errorf("expected order %v triggered as resting order 42, got %+v", id, co)
+	}
+	if symbols := engine.Symbols(); len(symbols) != 0 {
+		t.Errorf("expected no symbols to watch, got %v", symbols)
+	}
+}
+
+func TestConditionalOrderOptionStopTick(t *testing.T) {
+	submitter := &recordingSubmitter{}
+	engine, err := NewConditionalOrderEngine(submitter, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := Order{Class: Option, Symbol: "AAPL", OptionSymbol: "AAPL240621C00190000",
+		Side: SellToClose, Type: StopOrder, Quantity: 1}
+	if _, err := engine.Add(ConditionalOrder{
+		Kind:           TrailingStop,
+		Order:          order,
+		TrailAmount:    0.5,
+		RestingOrderId: 7,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, price := range []float64{5, 5.03, 5.05, 5.12} {
+		engine.HandleTrade(&TradeEvent{Symbol: order.OptionSymbol, Price: price})
+	}
+
+	// Moves of 3 and 2 cents are less than the 10 cent tick,
+	// but accumulate to 12 cents.
+	var stops []float64
+	for _, o := range submitter.changed {
+		stops = append(stops, o.StopPrice)
+	}
+	expected := []float64{4.5, 4.62}
+	if len(stops) != len(expected) {
+		t.Fatalf("expected stop changes %v, got %v", expected, stops)
+	}
+	for i := range expected {
+		assertFloat(t, "stop", stops[i], expected[i])
+	}
+}
+
+func TestConditionalOrderEngineStopTwice(t *testing.T) {
+	engine, err := NewConditionalOrderEngine(&recordingSubmitter{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.Start(time.Hour)
+	engine.Stop()
+	engine.Stop()
+}
@@ -0,0 +1,295 @@
+package tradier
+
+import (
+	"sync"
+	"time"
+)
+
+// HistoryChunk is a time range fetched by a single request.
+type HistoryChunk struct {
+	Start time.Time
+	End   time.Time
+}
+
+// HistoryDownloader fetches large ranges of intraday time sales by
+// splitting them into chunks aligned to trading sessions, which are
+// then fetched concurrently.
+type HistoryDownloader struct {
+	client   *Client
+	calendar *TradingCalendar
+
+	// Maximum number of concurrent requests.
+	Concurrency int
+	// Number of times a failed chunk is retried before giving up.
+	Retries int
+	// Number of trading days per chunk. If zero, a default is
+	// chosen based on the interval.
+	ChunkDays int
+}
+
+func NewHistoryDownloader(client *Client) *HistoryDownloader {
+	return &HistoryDownloader{
+		client:      client,
+		calendar:    NewTradingCalendar(client),
+		Concurrency: defaultConcurrency,
+		Retries:     3,
+	}
+}
+
+// Number of trading days that can be fetched in one request
+// without exceeding Tradier's maximum response size.
+func defaultChunkDays(interval Interval) int {
+	switch interval {
+	case IntervalTick:
+		return 1
+	case IntervalMinute:
+		return 5
+	case Interval5Min:
+		return 10
+	default:
+		return 20
+	}
+}
+
+// Plan splits [start, end] into chunks of whole trading sessions.
+// Daily, weekly and monthly intervals are fetched in a single chunk.
+func (hd *HistoryDownloader) Plan(interval Interval, start, end time.Time) ([]HistoryChunk, error) {
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if IsDailyInterval(interval) {
+		return []HistoryChunk{{start, end}}, nil
+	}
+
+	days, err := hd.calendar.TradingDaysBetween(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkDays := hd.ChunkDays
+	if chunkDays <= 0 {
+		chunkDays = defaultChunkDays(interval)
+	}
+
+	var chunks []HistoryChunk
+	for i := 0; i < len(days); i += chunkDays {
+		j := i + chunkDays
+		if j > len(days) {
+			j = len(days)
+		}
+
+		chunk := HistoryChunk{sessionStart(days[i]), sessionEnd(days[j-1])}
+		if chunk.Start.Before(start) {
+			chunk.Start = start
+		}
+		if chunk.End.After(end) {
+			chunk.End = end
+		}
+		if chunk.Start.Before(chunk.End) {
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	return chunks, nil
+}
+
+// Download fetches the time sales for the symbol in [start, end]. Chunks
+// are fetched concurrently, but are returned by the iterator in order,
+// with bars duplicated at chunk boundaries removed.
+func (hd *HistoryDownloader) Download(symbol string, interval Interval, start, end time.Time) (*TimeSaleIterator, error) {
+	chunks, err := hd.Plan(interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	it := &TimeSaleIterator{
+		results: make(chan chunkResult),
+		done:    make(chan struct{}),
+	}
+	go hd.run(symbol, interval, chunks, it)
+	return it, nil
+}
+
+type chunkResult struct {
+	chunk     HistoryChunk
+	timeSales []TimeSale
+	err       error
+}
+
+func (hd *HistoryDownloader) run(symbol string, interval Interval, chunks []HistoryChunk, it *TimeSaleIterator) {
+	defer close(it.results)
+
+	workers := hd.Concurrency
+	if workers <= 0 {
+		workers = defaultConcurrency
+	}
+	sem := make(chan struct{}, workers)
+	// Bounds the number of chunks fetched but not yet consumed.
+	window := make(chan struct{}, 2*workers)
+	pending := make([]chan chunkResult, len(chunks))
+	for i := range pending {
+		pending[i] = make(chan chunkResult, 1)
+	}
+
+	go func() {
+		for i, chunk := range chunks {
+			select {
+			case window <- struct{}{}:
+			case <-it.done:
+				return
+			}
+
+			go func(i int, chunk HistoryChunk) {
+				select {
+				case sem <- struct{}{}:
+				case <-it.done:
+					return
+				}
+				tss, err := hd.fetch(symbol, interval, chunk, it.done)
+				<-sem
+				pending[i] <- chunkResult{chunk, tss, err}
+			}(i, chunk)
+		}
+	}()
+
+	var dedup boundaryFilter
+	for i := range chunks {
+		var r chunkResult
+		select {
+		case r = <-pending[i]:
+		case <-it.done:
+			return
+		}
+		<-window
+
+		if r.err == nil {
+			sortTimeSales(r.timeSales)
+			r.timeSales = dedup.filter(r.timeSales, interval)
+		}
+
+		select {
+		case it.results <- r:
+		case <-it.done:
+			return
+		}
+		if r.err != nil {
+			return
+		}
+	}
+}
+
+func (hd *HistoryDownloader) fetch(symbol string, interval Interval, chunk HistoryChunk, done chan struct{}) ([]TimeSale, error) {
+	var tss []TimeSale
+	var err error
+	for attempt := 0; attempt <= hd.Retries; attempt++ {
+		if attempt > 0 {
+			Logger.Printf("retrying %v time sales from %v to %v: %v\n",
+				symbol, chunk.Start, chunk.End, err)
+			select {
+			case <-time.After(time.Duration(attempt) * time.Second):
+			case <-done:
+				return nil, err
+			}
+		}
+
+		tss, err = hd.client.GetTimeSales(symbol, interval, chunk.Start, chunk.End)
+		if err == nil {
+			return tss, nil
+		}
+	}
+
+	return nil, err
+}
+
+// Removes bars from the start of a chunk that were also at the
+// end of the previous chunk.
+type boundaryFilter struct {
+	last time.Time
+	// Ticks at the last time of the previous chunk.
+	lastTicks map[tickKey]bool
+}
+
+func (bf *boundaryFilter) filter(tss []TimeSale, interval Interval) []TimeSale {
+	result := tss[:0]
+	for _, ts := range tss {
+		t := TimeSaleTime(ts)
+		if t.Before(bf.last) {
+			continue
+		}
+		if t.Equal(bf.last) && (interval != IntervalTick || bf.lastTicks[newTickKey(ts)]) {
+			continue
+		}
+		result = append(result, ts)
+	}
+
+	if len(result) > 0 {
+		bf.last = TimeSaleTime(result[len(result)-1])
+		bf.lastTicks = make(map[tickKey]bool)
+		for i := len(result) - 1; i >= 0 && TimeSaleTime(result[i]).Equal(bf.last); i-- {
+			bf.lastTicks[newTickKey(result[i])] = true
+		}
+	}
+
+	return result
+}
+
+// TimeSaleIterator returns the results of a download one chunk at a time.
+//
+//	it, err := downloader.Download(symbol, interval, start, end)
+//	...
+//	defer it.Close()
+//	for it.Next() {
+//		process(it.TimeSales())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type TimeSaleIterator struct {
+	results chan chunkResult
+	done    chan struct{}
+	once    sync.Once
+
+	current chunkResult
+	err     error
+}
+
+// Next advances to the next chunk, returning false when there are
+// no more chunks or an error occurred.
+func (it *TimeSaleIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	r, ok := <-it.results
+	if !ok {
+		return false
+	}
+	if r.err != nil {
+		it.err = r.err
+		it.Close()
+		return false
+	}
+
+	it.current = r
+	return true
+}
+
+// TimeSales returns the time sales of the current chunk.
+func (it *TimeSaleIterator) TimeSales() []TimeSale {
+	return it.current.timeSales
+}
+
+// Chunk returns the time range of the current chunk.
+func (it *TimeSaleIterator) Chunk() HistoryChunk {
+	return it.current.chunk
+}
+
+// Err returns the error that stopped iteration, if any.
+func (it *TimeSaleIterator) Err() error {
+	return it.err
+}
+
+// Close stops the download. It is safe to call more than once.
+func (it *TimeSaleIterator) Close() {
+	it.once.Do(func() { close(it.done) })
+}
@@ -372,7 +372,7 @@ type BalanceSheet struct {
 	FiscalYearEnd                                 *string  `json:"fiscal_year_end"`
 	GainsLossesNotAffectingRetainedEarnings       *float64 `json:"gains_losses_not_affecting_retained_earnings"`
 	Goodwill                                      *float64 `json:"goodwill"`
-	GoodwillAndOtherIntangibleAssets              *float64 `json:"goodwill_and_other_int64angible_assets"`
+	GoodwillAndOtherIntangibleAssets              *float64 `json:"goodwill_and_other_intangible_assets"`
 	GrossPPE                                      *float64 `json:"gross_p_p_e"`
 	Inventory                                     *float64 `json:"inventory"`
 	InvestedCapital                               *float64 `json:"invested_capital"`
@@ -392,7 +392,7 @@ type BalanceSheet struct {
 	OrdinarySharesNumber                          *float64 `json:"ordinary_shares_number"`
 	OtherCurrentAssets                            *float64 `json:"other_current_assets"`
 	OtherCurrentBorrowings                        *float64 `json:"other_current_borrowings"`
-	OtherIntangibleAssets                         *float64 `json:"other_int64angible_assets"`
+	OtherIntangibleAssets                         *float64 `json:"other_intangible_assets"`
 	OtherNonCurrentAssets                         *float64 `json:"other_non_current_assets"`
 	OtherNonCurrentLiabilities                    *float64 `json:"other_non_current_liabilities"`
 	OtherReceivables                              *float64 `json:"other_receivables"`
@@ -411,12 +411,12 @@ type BalanceSheet struct {
 	TotalCapitalization                           *float64 `json:"total_capitalization"`
 	TotalDebt                                     *float64 `json:"total_debt"`
 	TotalEquity                                   *float64 `json:"total_equity"`
-	TotalEquityGrossMinorityInterest              *float64 `json:"total_equity_gross_minority_int64erest"`
+	TotalEquityGrossMinorityInterest              *float64 `json:"total_equity_gross_minority_interest"`
 	TotalLiabilities                              *float64 `json:"total_liabilities"`
-	TotalLiabilitiesNetMinorityInterest           *float64 `json:"total_liabilities_net_minority_int64erest"`
+	TotalLiabilitiesNetMinorityInterest           *float64 `json:"total_liabilities_net_minority_interest"`
 	TotalNonCurrentAssets                         *float64 `json:"total_non_current_assets"`
 	TotalNonCurrentLiabilities                    *float64 `json:"total_non_current_liabilities"`
-	TotalNonCurrentLiabilitiesNetMinorityInterest *float64 `json:"total_non_current_liabilities_net_minority_int64erest"`
+	TotalNonCurrentLiabilitiesNetMinorityInterest *float64 `json:"total_non_current_liabilities_net_minority_interest"`
 	WorkingCapital                                *float64 `json:"working_capital"`
 }
 
@@ -448,14 +448,14 @@ type CashFlowStatement struct {
 	ForeignSales                      *float64 `json:"foreign_sales"`
 	FreeCashFlow                      *float64 `json:"free_cash_flow"`
 	IncomeTaxPaidSupplementalData     *float64 `json:"income_tax_paid_supplemental_data"`
-	InterestPaidSupplementalData      *float64 `json:"int64erest_paid_supplemental_data"`
+	InterestPaidSupplementalData      *float64 `json:"interest_paid_supplemental_data"`
 	InvestingCashFlow                 *float64 `json:"investing_cash_flow"`
 	IssuanceOfCapitalStock            *float64 `json:"issuance_of_capital_stock"`
 	NetBusinessPurchaseAndSale        *float64 `json:"net_business_purchase_and_sale"`
 	NetCommonStockIssuance            *float64 `json:"net_common_stock_issuance"`
 	NetIncome                         *float64 `json:"net_income"`
 	NetIncomeFromContinuingOperations *float64 `json:"net_income_from_continuing_operations"`
-	NetIntangiblesPurchaseAndSale     *float64 `json:"net_int64angibles_purchase_and_sale"`
+	NetIntangiblesPurchaseAndSale     *float64 `json:"net_intangibles_purchase_and_sale"`
 	NetInvestmentPurchaseAndSale      *float64 `json:"net_investment_purchase_and_sale"`
 	NetIssuancePaymentsOfDebt         *float64 `json:"net_issuance_payments_of_debt"`
 	NetOtherFinancingCharges          *float64 `json:"net_other_financing_charges"`
@@ -468,7 +468,7 @@ type CashFlowStatement struct {
 	Period                            *string  `json:"period"`
 	PeriodEndingDate                  *string  `json:"period_ending_date"`
 	PurchaseOfBusiness                *float64 `json:"purchase_of_business"`
-	PurchaseOfIntangibles             *float64 `json:"purchase_of_int64angibles"`
+	PurchaseOfIntangibles             *float64 `json:"purchase_of_intangibles"`
 	PurchaseOfInvestment              *float64 `json:"purchase_of_investment"`
 	PurchaseOfPPE                     *float64 `json:"purchase_of_p_p_e"`
 	ReportType                        *string  `json:"report_type"`
@@ -487,19 +487,19 @@ type IncomeStatement struct {
 	FiscalYearEnd                                       *string  `json:"fiscal_year_end"`
 	FormType                                            *string  `json:"form_type"`
 	GrossProfit                                         *float64 `json:"gross_profit"`
-	InterestExpense                                     *float64 `json:"int64erest_expense"`
-	InterestExpenseNonOperating                         *float64 `json:"int64erest_expense"`
-	InterestIncome                                      *float64 `json:"int64erest_income"`
-	InterestIncomeNonOperating                          *float64 `json:"int64erest_income_non_operating"`
-	InterestAndSimilarIncome                            *float64 `json:"int64erestand_similar_income"`
+	InterestExpense                                     *float64 `json:"interest_expense"`
+	InterestExpenseNonOperating                         *float64 `json:"interest_expense_non_operating"`
+	InterestIncome                                      *float64 `json:"interest_income"`
+	InterestIncomeNonOperating                          *float64 `json:"interest_income_non_operating"`
+	InterestAndSimilarIncome                            *float64 `json:"interestand_similar_income"`
 	NetIncome                                           *float64 `json:"net_income"`
 	NetIncomeCommonStockholders                         *float64 `json:"net_income_common_stockholders"`
 	NetIncomeContinuousOperations                       *float64 `json:"net_income_continuous_operations"`
 	NetIncomeFromContinuingAndDiscontinuedOperation     *float64 `json:"net_income_from_continuing_and_discontinued_operation"`
-	NetIncomeFromContinuingOperationNetMinorityInterest *float64 `json:"net_income_from_continuing_operation_net_minority_int64erest"`
-	NetIncomeIncludingNoncontrollingInterests           *float64 `json:"net_income_including_noncontrolling_int64erests"`
-	NetInterestIncome                                   *float64 `json:"net_int64erest_income"`
-	NetNonOperatingInterestIncomeExpense                *float64 `json:"net_non_operating_int64erest_income_expense"`
+	NetIncomeFromContinuingOperationNetMinorityInterest *float64 `json:"net_income_from_continuing_operation_net_minority_interest"`
+	NetIncomeIncludingNoncontrollingInterests           *float64 `json:"net_income_including_noncontrolling_interests"`
+	NetInterestIncome                                   *float64 `json:"net_interest_income"`
+	NetNonOperatingInterestIncomeExpense                *float64 `json:"net_non_operating_interest_income_expense"`
 	NonOperatingExpenses                                *float64 `json:"non_operating_expenses"`
 	NonOperatingIncome                                  *float64 `json:"non_operating_income"`
 	NormalizedEBITDA                                    *float64 `json:"normalized_e_b_i_t_d_a"`
@@ -678,7 +678,7 @@ type OperationRatio struct {
 	FiscalYearEnd                 *string  `json:"fiscal_year_end"`
 	FixAssetsTurnover             *float64 `json:"fix_assets_turonver"`
 	GrossMargin                   *float64 `json:"gross_margin"`
-	InterestCoverage              *float64 `json:"int64erest_coverage"`
+	InterestCoverage              *float64 `json:"interest_coverage"`
 	InventoryTurnover             *float64 `json:"inventory_turnover"`
 	LongTermDebtEquityRatio       *float64 `json:"long_term_debt_equity_ratio"`
 	LongTermDebtTotalCapitalRatio *float64 `json:"long_term_debt_total_capital_ratio"`
@@ -0,0 +1,481 @@
+package tradier
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the rules enforced by OrderGuard.
+const (
+	RuleMaxNotional     = "max_notional"
+	RuleMaxPositionSize = "max_position_size"
+	RulePriceCollar     = "price_collar"
+	RuleAllowedSymbols  = "allowed_symbols"
+	RuleAllowedClasses  = "allowed_classes"
+	RuleMaxDailyLoss    = "max_daily_loss"
+	RuleMaxOrderRate    = "max_order_rate"
+)
+
+// GuardRules configures the checks applied by an OrderGuard.
+// Zero values disable the corresponding rule.
+type GuardRules struct {
+	// Maximum notional value (price * quantity * multiplier) of a single order.
+	// Multileg and combo orders are valued at their net debit or credit
+	// at current marks, and OTO, OCO and OTOCO orders at their largest leg.
+	// Orders that cannot be valued because a quote is missing are rejected.
+	MaxNotional float64
+	// Maximum absolute position size in any symbol after the order fills.
+	MaxPositionSize float64
+	// Per-symbol overrides for MaxPositionSize.
+	PositionLimits map[string]float64
+	// Maximum fractional distance of a limit price through the far side
+	// of the NBBO, i.e. 0.05 rejects buys priced more than 5% above the ask.
+	// Limit orders without a quote are rejected.
+	PriceCollar float64
+	// If non-empty, only these symbols (or option underlyings) may be traded.
+	AllowedSymbols []string
+	// If non-empty, only these order classes may be used.
+	AllowedClasses []string
+	// Reject new orders once the account's closed P&L for the day is
+	// below -MaxDailyLoss.
+	MaxDailyLoss float64
+	// Maximum number of orders submitted per RateWindow.
+	MaxOrderRate int
+	RateWindow   time.Duration
+}
+
+// GuardViolation describes a single rule that an order failed.
+type GuardViolation struct {
+	Rule   string
+	Reason string
+}
+
+// GuardError is returned by OrderGuard when an order is rejected.
+type GuardError struct {
+	Order      Order
+	Violations []GuardViolation
+}
+
+func (ge *GuardError) Error() string {
+	reasons := make([]string, len(ge.Violations))
+	for i, v := range ge.Violations {
+		reasons[i] = v.Rule + ": " + v.Reason
+	}
+	return "order rejected by guard: " + strings.Join(reasons, "; ")
+}
+
+// GuardAuditEntry records a single order submission attempt through an OrderGuard.
+type GuardAuditEntry struct {
+	Time       time.Time
+	Action     string // "place" or "change"
+	OrderId    int
+	Order      Order
+	Violations []GuardViolation
+	Err        error
+}
+
+// OrderGuard wraps order submission with pre-trade risk checks.
+// Orders that violate any rule are rejected with a *GuardError
+// before they are sent to Tradier.
+type OrderGuard struct {
+	client *Client
+	rules  GuardRules
+
+	// If set, called with every audit entry as it is recorded.
+	OnAudit func(entry GuardAuditEntry)
+
+	mu        sync.Mutex
+	submitted []time.Time
+	audit     []GuardAuditEntry
+}
+
+func NewOrderGuard(client *Client, rules GuardRules) *OrderGuard {
+	if rules.MaxOrderRate > 0 && rules.RateWindow == 0 {
+		rules.RateWindow = time.Minute
+	}
+
+	return &OrderGuard{
+		client: client,
+		rules:  rules,
+	}
+}
+
+// PlaceOrder checks the order against the guard's rules and places it if it passes.
+func (og *OrderGuard) PlaceOrder(order Order) (int, error) {
+	violations, err := og.Check(order)
+	if err == nil && len(violations) == 0 {
+		violations = og.reserveRate()
+	}
+	if err != nil || len(violations) > 0 {
+		if err == nil {
+			err = &GuardError{Order: order, Violations: violations}
+		}
+		og.record("place", 0, order, violations, err)
+		return 0, err
+	}
+
+	orderId, err := og.client.PlaceOrder(order)
+	og.record("place", orderId, order, nil, err)
+	return orderId, err
+}
+
+// ChangeOrder checks the modified order against the guard's rules
+// and submits the change if it passes.
+func (og *OrderGuard) ChangeOrder(orderId int, order Order) error {
+	existing, err := og.client.GetOrderStatus(orderId)
+	if err != nil {
+		og.record("change", orderId, order, nil, err)
+		return err
+	}
+
+	// Changes can only modify the type, duration and prices.
+	merged := *existing
+	merged.Type = order.Type
+	merged.Duration = order.Duration
+	merged.Price = order.Price
+	merged.StopPrice = order.StopPrice
+	// Open orders are not part of positions, so the modified order is
+	// checked like a new order for its unfilled quantity. The filled
+	// quantity is already included in the current position.
+	merged.Quantity = existing.RemainingQuantity
+
+	violations, err := og.Check(merged)
+	if err == nil && len(violations) == 0 {
+		violations = og.reserveRate()
+	}
+	if err != nil || len(violations) > 0 {
+		if err == nil {
+			err = &GuardError{Order: merged, Violations: violations}
+		}
+		og.record("change", orderId, merged, violations, err)
+		return err
+	}
+
+	err = og.client.ChangeOrder(orderId, order)
+	og.record("change", orderId, merged, nil, err)
+	return err
+}
+
+// AuditLog returns all audit entries recorded so far.
+func (og *OrderGuard) AuditLog() []GuardAuditEntry {
+	og.mu.Lock()
+	defer og.mu.Unlock()
+	result := make([]GuardAuditEntry, len(og.audit))
+	copy(result, og.audit)
+	return result
+}
+
+func (og *OrderGuard) record(action string, orderId int, order Order, violations []GuardViolation, err error) {
+	entry := GuardAuditEntry{
+		Time:       time.Now(),
+		Action:     action,
+		OrderId:    orderId,
+		Order:      order,
+		Violations: violations,
+		Err:        err,
+	}
+
+	og.mu.Lock()
+	og.audit = append(og.audit, entry)
+	og.mu.Unlock()
+
+	if err != nil {
+		Logger.Printf("guard: %v order %v (%v %v %v) failed: %v\n",
+			action, orderId, order.Side, order.Quantity, order.Symbol, err)
+	}
+	if og.OnAudit != nil {
+		og.OnAudit(entry)
+	}
+}
+
+// Record an order submission against the rate limit, or return a violation
+// if the rate limit has been exceeded.
+func (og *OrderGuard) reserveRate() []GuardViolation {
+	if og.rules.MaxOrderRate <= 0 {
+		return nil
+	}
+
+	og.mu.Lock()
+	defer og.mu.Unlock()
+	now := time.Now()
+	cutoff := now.Add(-og.rules.RateWindow)
+	recent := og.submitted[:0]
+	for _, t := range og.submitted {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	og.submitted = recent
+
+	if len(og.submitted) >= og.rules.MaxOrderRate {
+		return []GuardViolation{{
+			Rule: RuleMaxOrderRate,
+			Reason: fmt.Sprintf("%d orders submitted in the last %v",
+				len(og.submitted), og.rules.RateWindow),
+		}}
+	}
+	og.submitted = append(og.submitted, now)
+	return nil
+}
+
+// Check returns the rules that the given order would violate, without
+// submitting it. The order rate limit is not checked. An error is returned
+// if the data needed to evaluate the rules could not be fetched.
+func (og *OrderGuard) Check(order Order) ([]GuardViolation, error) {
+	var violations []GuardViolation
+	legs := orderLegs(order)
+
+	if len(og.rules.AllowedClasses) > 0 && !containsString(og.rules.AllowedClasses, order.Class) {
+		violations = append(violations, GuardViolation{
+			Rule:   RuleAllowedClasses,
+			Reason: fmt.Sprintf("order class %v is not allowed", order.Class),
+		})
+	}
+
+	if len(og.rules.AllowedSymbols) > 0 {
+		for _, leg := range legs {
+			symbol := legSymbol(leg)
+			underlying := symbol
+			if sym, err := ParseOptionSymbol(symbol); err == nil {
+				underlying = sym.Underlying()
+			}
+			if !containsString(og.rules.AllowedSymbols, symbol) &&
+				!containsString(og.rules.AllowedSymbols, underlying) &&
+				!containsString(og.rules.AllowedSymbols, leg.Symbol) {
+				violations = append(violations, GuardViolation{
+					Rule:   RuleAllowedSymbols,
+					Reason: fmt.Sprintf("symbol %v is not allowed", symbol),
+				})
+			}
+		}
+	}
+
+	if og.rules.MaxDailyLoss > 0 {
+		balances, err := og.client.GetAccountBalances()
+		if err != nil {
+			return nil, err
+		}
+		if balances.ClosePL < -og.rules.MaxDailyLoss {
+			violations = append(violations, GuardViolation{
+				Rule: RuleMaxDailyLoss,
+				Reason: fmt.Sprintf("closed P&L $%.2f exceeds daily loss limit $%.2f",
+					balances.ClosePL, og.rules.MaxDailyLoss),
+			})
+		}
+	}
+
+	if og.rules.MaxNotional > 0 || og.rules.PriceCollar > 0 {
+		symbols := make([]string, 0, len(legs))
+		for _, leg := range legs {
+			symbols = append(symbols, legSymbol(leg))
+		}
+		quotes, err := og.client.getQuotesChunked(symbols)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, og.checkPrices(order, legs, quotes)...)
+	}
+
+	if og.rules.MaxPositionSize > 0 || len(og.rules.PositionLimits) > 0 {
+		positions, err := og.client.GetAccountPositions()
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, og.checkPositions(legs, positions)...)
+	}
+
+	return violations, nil
+}
+
+func (og *OrderGuard) checkPrices(order Order, legs []Order, quotes map[string]*Quote) []GuardViolation {
+	var violations []GuardViolation
+	// A quote that is needed to evaluate a rule is missing. The guard
+	// fails closed rather than letting the order through unchecked.
+	missingQuote := func(rule, symbol string) {
+		violations = append(violations, GuardViolation{
+			Rule:   rule,
+			Reason: fmt.Sprintf("no quote for %v", symbol),
+		})
+	}
+
+	var notional float64
+	for _, leg := range legs {
+		symbol := legSymbol(leg)
+		q := quotes[symbol]
+		if og.rules.PriceCollar > 0 && leg.Price > 0 {
+			if q == nil {
+				missingQuote(RulePriceCollar, symbol)
+			} else if v, ok := og.checkCollar(symbol, leg.Side, leg.Price, q); !ok {
+				violations = append(violations, v)
+			}
+		}
+		if order.Class == Multileg || order.Class == Combo {
+			continue
+		}
+
+		price := leg.Price
+		if leg.Type == StopOrder {
+			price = leg.StopPrice
+		}
+		if price == 0 && q != nil {
+			// Market orders are valued at the far side of the NBBO.
+			price = QuoteMark(q)
+			if SideSign(leg.Side) > 0 && q.Ask > 0 {
+				price = q.Ask
+			} else if SideSign(leg.Side) < 0 && q.Bid > 0 {
+				price = q.Bid
+			}
+		}
+		if price <= 0 && og.rules.MaxNotional > 0 {
+			missingQuote(RuleMaxNotional, symbol)
+		}
+
+		multiplier := 1.0
+		if leg.OptionSymbol != "" || order.Class == Option {
+			multiplier = legMultiplier(q)
+		}
+		// Only one branch of an OCO order can fill, and the later legs of
+		// OTO and OTOCO orders exit the position opened by the first, so
+		// these orders are valued at their largest leg.
+		notional = math.Max(notional, math.Abs(price*leg.Quantity*multiplier))
+	}
+
+	// Multileg and combo orders are valued at their net debit or credit
+	// at current marks, so that spreads are not counted at the gross
+	// value of their legs.
+	if order.Class == Multileg || order.Class == Combo {
+		var net float64
+		for _, leg := range legs {
+			q := quotes[legSymbol(leg)]
+			if q == nil || QuoteMark(q) <= 0 {
+				if og.rules.MaxNotional > 0 {
+					missingQuote(RuleMaxNotional, legSymbol(leg))
+				}
+				continue
+			}
+			multiplier := 1.0
+			if leg.OptionSymbol != "" {
+				multiplier = legMultiplier(q)
+			}
+			net += SideSign(leg.Side) * QuoteMark(q) * leg.Quantity * multiplier
+		}
+		notional = math.Abs(net)
+	}
+
+	if og.rules.MaxNotional > 0 && notional > og.rules.MaxNotional {
+		violations = append(violations, GuardViolation{
+			Rule: RuleMaxNotional,
+			Reason: fmt.Sprintf("order notional $%.2f exceeds limit $%.2f",
+				notional, og.rules.MaxNotional),
+		})
+	}
+
+	return violations
+}
+
+// The contract multiplier of an option, from its quote if available.
+func legMultiplier(q *Quote) float64 {
+	if q != nil && q.ContractSize > 0 {
+		return float64(q.ContractSize)
+	}
+	return defaultContractSize
+}
+
+func (og *OrderGuard) checkCollar(symbol, side string, price float64, q *Quote) (GuardViolation, bool) {
+	collar := og.rules.PriceCollar
+	if SideSign(side) > 0 && q.Ask > 0 && price > q.Ask*(1+collar) {
+		return GuardViolation{
+			Rule: RulePriceCollar,
+			Reason: fmt.Sprintf("%v buy price %.2f is more than %.1f%% above ask %.2f",
+				symbol, price, 100*collar, q.Ask),
+		}, false
+	} else if SideSign(side) < 0 && q.Bid > 0 && price < q.Bid*(1-collar) {
+		return GuardViolation{
+			Rule: RulePriceCollar,
+			Reason: fmt.Sprintf("%v sell price %.2f is more than %.1f%% below bid %.2f",
+				symbol, price, 100*collar, q.Bid),
+		}, false
+	}
+	return GuardViolation{}, true
+}
+
+func (og *OrderGuard) checkPositions(legs []Order, positions []*Position) []GuardViolation {
+	current := make(map[string]float64, len(positions))
+	for _, p := range positions {
+		current[p.Symbol] += p.Quantity
+	}
+
+	var violations []GuardViolation
+	for _, leg := range legs {
+		symbol := legSymbol(leg)
+		limit, ok := og.rules.PositionLimits[symbol]
+		if !ok {
+			limit = og.rules.MaxPositionSize
+		}
+		if limit <= 0 {
+			continue
+		}
+
+		after := current[symbol] + SideSign(leg.Side)*leg.Quantity
+		if math.Abs(after) > limit {
+			violations = append(violations, GuardViolation{
+				Rule: RuleMaxPositionSize,
+				Reason: fmt.Sprintf("%v position would be %v, exceeding limit %v",
+					symbol, after, limit),
+			})
+		}
+	}
+	return violations
+}
+
+// SideSign returns +1 for buy sides and -1 for sell sides.
+func SideSign(side string) float64 {
+	switch side {
+	case Buy, BuyToCover, BuyToOpen, BuyToClose:
+		return 1
+	case Sell, SellShort, SellToOpen, SellToClose:
+		return -1
+	}
+	return 0
+}
+
+// Return the individual legs of an order, or the order itself if
+// it is a single-leg order.
+func orderLegs(order Order) []Order {
+	switch order.Class {
+	case Multileg, Combo:
+		legs := make([]Order, len(order.Legs))
+		for i, leg := range order.Legs {
+			if leg.Symbol == "" {
+				leg.Symbol = order.Symbol
+			}
+			leg.Type = order.Type
+			legs[i] = leg
+		}
+		return legs
+	case OneTriggersOther, OneCancelsOther, OneTriggersOneCancelsOther:
+		return order.Legs
+	}
+	return []Order{order}
+}
+
+// The symbol traded by the given leg: the option symbol for options,
+// otherwise the equity symbol.
+func legSymbol(leg Order) string {
+	if leg.OptionSymbol != "" {
+		return leg.OptionSymbol
+	}
+	return leg.Symbol
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package tradier
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const guardTestQuotes = `{"quotes":{"quote":[
+	{"symbol":"AAPL","type":"stock","bid":149.9,"ask":150.0,"last":150.0},
+	{"symbol":"SPY240621C00500000","type":"option","bid":4.9,"ask":5.1,"last":5.0},
+	{"symbol":"SPY240621C00510000","type":"option","bid":2.9,"ask":3.1,"last":3.0}
+]}}`
+
+// Return a client serving fixed quotes, balances and positions for OrderGuard.
+func newGuardTestClient(t *testing.T) *Client {
+	responses := map[string]string{
+		"/v1/markets/quotes":               guardTestQuotes,
+		"/v1/accounts/VA00000000/balances": `{"balances":{"account_type":"margin","close_pl":-600}}`,
+		"/v1/accounts/VA00000000/positions": `{"positions":{"position":` +
+			`{"symbol":"AAPL","quantity":80,"cost_basis":12000,"id":1}}}`,
+	}
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+}
+
+func stockOrder(symbol, side, orderType string, quantity, price float64) Order {
+	return Order{
+		Class:    Equity,
+		Symbol:   symbol,
+		Side:     side,
+		Quantity: quantity,
+		Type:     orderType,
+		Price:    price,
+		Duration: Day,
+	}
+}
+
+func TestOrderGuardCheck(t *testing.T) {
+	vertical := NewVerticalSpread("SPY", testNear, Call, 500, 510, 2)
+	unquotedSpread := NewVerticalSpread("SPY", testNear, Call, 500, 520, 2)
+	bracket, err := NewBracketOrder(
+		stockOrder("AAPL", Buy, LimitOrder, 50, 150),
+		stockOrder("AAPL", Sell, LimitOrder, 50, 160),
+		Order{Class: Equity, Symbol: "AAPL", Side: Sell, Quantity: 50, Type: StopOrder, StopPrice: 140, Duration: Day})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		rules    GuardRules
+		order    Order
+		expected []string
+	}{
+		{
+			name:     "allowed class",
+			rules:    GuardRules{AllowedClasses: []string{Equity}},
+			order:    stockOrder("AAPL", Buy, MarketOrder, 1, 0),
+			expected: nil,
+		},
+		{
+			name:     "disallowed class",
+			rules:    GuardRules{AllowedClasses: []string{Equity}},
+			order:    vertical,
+			expected: []string{RuleAllowedClasses},
+		},
+		{
+			name:     "allowed option underlying",
+			rules:    GuardRules{AllowedSymbols: []string{"SPY"}},
+			order:    vertical,
+			expected: nil,
+		},
+		{
+			name:     "disallowed symbol",
+			rules:    GuardRules{AllowedSymbols: []string{"SPY"}},
+			order:    stockOrder("AAPL", Buy, MarketOrder, 1, 0),
+			expected: []string{RuleAllowedSymbols},
+		},
+		{
+			name:     "daily loss within limit",
+			rules:    GuardRules{MaxDailyLoss: 1000},
+			order:    stockOrder("AAPL", Buy, MarketOrder, 1, 0),
+			expected: nil,
+		},
+		{
+			name:     "daily loss exceeded",
+			rules:    GuardRules{MaxDailyLoss: 500},
+			order:    stockOrder("AAPL", Buy, MarketOrder, 1, 0),
+			expected: []string{RuleMaxDailyLoss},
+		},
+		{
+			name:     "market order notional at the ask",
+			rules:    GuardRules{MaxNotional: 7500},
+			order:    stockOrder("AAPL", Buy, MarketOrder, 50, 0),
+			expected: nil,
+		},
+		{
+			name:     "market order notional exceeded",
+			rules:    GuardRules{MaxNotional: 7499},
+			order:    stockOrder("AAPL", Buy, MarketOrder, 50, 0),
+			expected: []string{RuleMaxNotional},
+		},
+		{
+			name:     "market order without quote",
+			rules:    GuardRules{MaxNotional: 10000},
+			order:    stockOrder("XYZ", Buy, MarketOrder, 1, 0),
+			expected: []string{RuleMaxNotional},
+		},
+		{
+			// Net debit of (5 - 3) * 2 * 100 = $400; the gross is $1600.
+			name:     "multileg valued at net",
+			rules:    GuardRules{MaxNotional: 400},
+			order:    vertical,
+			expected: nil,
+		},
+		{
+			name:     "multileg net exceeded",
+			rules:    GuardRules{MaxNotional: 399},
+			order:    vertical,
+			expected: []string{RuleMaxNotional},
+		},
+		{
+			name:     "multileg leg without quote",
+			rules:    GuardRules{MaxNotional: 10000},
+			order:    unquotedSpread,
+			expected: []string{RuleMaxNotional},
+		},
+		{
+			// Valued at the $8000 take profit leg, not the $22500 sum of legs.
+			name:     "bracket valued at largest leg",
+			rules:    GuardRules{MaxNotional: 8000},
+			order:    bracket,
+			expected: nil,
+		},
+		{
+			name:     "bracket largest leg exceeded",
+			rules:    GuardRules{MaxNotional: 7999},
+			order:    bracket,
+			expected: []string{RuleMaxNotional},
+		},
+		{
+			name:     "limit within collar",
+			rules:    GuardRules{PriceCollar: 0.05},
+			order:    stockOrder("AAPL", Buy, LimitOrder, 1, 157.5),
+			expected: nil,
+		},
+		{
+			name:     "buy limit above collar",
+			rules:    GuardRules{PriceCollar: 0.05},
+			order:    stockOrder("AAPL", Buy, LimitOrder, 1, 158),
+			expected: []string{RulePriceCollar},
+		},
+		{
+			name:     "sell limit below collar",
+			rules:    GuardRules{PriceCollar: 0.05},
+			order:    stockOrder("AAPL", Sell, LimitOrder, 1, 142),
+			expected: []string{RulePriceCollar},
+		},
+		{
+			name:     "limit without quote",
+			rules:    GuardRules{PriceCollar: 0.05},
+			order:    stockOrder("XYZ", Buy, LimitOrder, 1, 10),
+			expected: []string{RulePriceCollar},
+		},
+		{
+			name:     "position within limit",
+			rules:    GuardRules{MaxPositionSize: 100},
+			order:    stockOrder("AAPL", Buy, MarketOrder, 20, 0),
+			expected: nil,
+		},
+		{
+			name:     "position limit exceeded",
+			rules:    GuardRules{MaxPositionSize: 100},
+			order:    stockOrder("AAPL", Buy, MarketOrder, 21, 0),
+			expected: []string{RuleMaxPositionSize},
+		},
+		{
+			name:     "per-symbol position limit",
+			rules:    GuardRules{MaxPositionSize: 1000, PositionLimits: map[string]float64{"AAPL": 50}},
+			order:    stockOrder("AAPL", Sell, MarketOrder, 20, 0),
+			expected: []string{RuleMaxPositionSize},
+		},
+	}
+
+	client := newGuardTestClient(t)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations, err := NewOrderGuard(client, tc.rules).Check(tc.order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rules []string
+			for _, v := range violations {
+				rules = append(rules, v.Rule)
+			}
+			if !reflect.DeepEqual(rules, tc.expected) {
+				t.Errorf("expected violations of %v, got %v", tc.expected, violations)
+			}
+		})
+	}
+}
+
+func TestOrderGuardRate(t *testing.T) {
+	og := NewOrderGuard(nil, GuardRules{MaxOrderRate: 2})
+	for i := 0; i < 2; i++ {
+		if v := og.reserveRate(); len(v) > 0 {
+			t.Fatalf("order %d: unexpected violations %v", i, v)
+		}
+	}
+	v := og.reserveRate()
+	if len(v) != 1 || v[0].Rule != RuleMaxOrderRate {
+		t.Errorf("expected %v violation, got %v", RuleMaxOrderRate, v)
+	}
+}
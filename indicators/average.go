@@ -0,0 +1,117 @@
+package indicators
+
+import (
+	"math"
+)
+
+// A fixed-size window of the most recent values.
+type window struct {
+	values []float64
+	next   int
+	full   bool
+}
+
+func newWindow(n int) *window {
+	if n < 1 {
+		n = 1
+	}
+	return &window{values: make([]float64, n)}
+}
+
+func (w *window) push(x float64) {
+	w.values[w.next] = x
+	w.next = (w.next + 1) % len(w.values)
+	if w.next == 0 {
+		w.full = true
+	}
+}
+
+// The mean and (population) standard deviation of the window.
+// The mean is recomputed on each call so that it does not drift.
+func (w *window) stats() (mean, stddev float64) {
+	for _, x := range w.values {
+		mean += x
+	}
+	mean /= float64(len(w.values))
+
+	var variance float64
+	for _, x := range w.values {
+		variance += (x - mean) * (x - mean)
+	}
+	variance /= float64(len(w.values))
+	return mean, math.Sqrt(variance)
+}
+
+// SMA is the simple moving average of the last N values.
+type SMA struct {
+	window *window
+	value  float64
+}
+
+func NewSMA(n int) *SMA {
+	return &SMA{window: newWindow(n), value: math.NaN()}
+}
+
+func (s *SMA) Update(x float64) float64 {
+	if math.IsNaN(x) {
+		return s.value
+	}
+
+	s.window.push(x)
+	if s.window.full {
+		s.value, _ = s.window.stats()
+	}
+	return s.value
+}
+
+func (s *SMA) Value() float64 {
+	return s.value
+}
+
+func (s *SMA) Ready() bool {
+	return !math.IsNaN(s.value)
+}
+
+// EMA is the exponential moving average with smoothing 2 / (N + 1).
+// It is seeded with the simple average of the first N values.
+type EMA struct {
+	n     int
+	alpha float64
+	seed  *SMA
+	value float64
+}
+
+func NewEMA(n int) *EMA {
+	return newEMA(n, 2/float64(n+1))
+}
+
+// Wilder's moving average, as used by RSI and ATR, is an EMA
+// with smoothing 1 / N.
+func newWilder(n int) *EMA {
+	return newEMA(n, 1/float64(n))
+}
+
+func newEMA(n int, alpha float64) *EMA {
+	return &EMA{n: n, alpha: alpha, seed: NewSMA(n), value: math.NaN()}
+}
+
+func (e *EMA) Update(x float64) float64 {
+	if math.IsNaN(x) {
+		return e.value
+	}
+
+	if math.IsNaN(e.value) {
+		e.value = e.seed.Update(x)
+	} else {
+		e.value += e.alpha * (x - e.value)
+	}
+	return e.value
+}
+
+func (e *EMA) Value() float64 {
+	return e.value
+}
+
+func (e *EMA) Ready() bool {
+	return !math.IsNaN(e.value)
+}
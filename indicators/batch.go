@@ -0,0 +1,77 @@
+package indicators
+
+import (
+	"github.com/timpalpant/go-tradier"
+)
+
+// The batch functions below compute an indicator over a series of time
+// sales, as returned by GetTimeSales, returning one value per bar.
+
+func bars(tss []tradier.TimeSale) []tradier.Bar {
+	return tradier.BarsFromTimeSales("", tss)
+}
+
+func closes(tss []tradier.TimeSale, update func(x float64) float64) []float64 {
+	bs := bars(tss)
+	result := make([]float64, len(bs))
+	for i, bar := range bs {
+		result[i] = update(bar.Close)
+	}
+	return result
+}
+
+func SMASeries(tss []tradier.TimeSale, n int) []float64 {
+	return closes(tss, NewSMA(n).Update)
+}
+
+func EMASeries(tss []tradier.TimeSale, n int) []float64 {
+	return closes(tss, NewEMA(n).Update)
+}
+
+func RSISeries(tss []tradier.TimeSale, n int) []float64 {
+	return closes(tss, NewRSI(n).Update)
+}
+
+func VolatilitySeries(tss []tradier.TimeSale, n int, periodsPerYear float64) []float64 {
+	return closes(tss, NewVolatility(n, periodsPerYear).Update)
+}
+
+func MACDSeries(tss []tradier.TimeSale, fast, slow, signal int) []MACDValue {
+	m := NewMACD(fast, slow, signal)
+	bs := bars(tss)
+	result := make([]MACDValue, len(bs))
+	for i, bar := range bs {
+		result[i] = m.Update(bar.Close)
+	}
+	return result
+}
+
+func BollingerBandsSeries(tss []tradier.TimeSale, n int, k float64) []BollingerValue {
+	b := NewBollingerBands(n, k)
+	bs := bars(tss)
+	result := make([]BollingerValue, len(bs))
+	for i, bar := range bs {
+		result[i] = b.Update(bar.Close)
+	}
+	return result
+}
+
+func ATRSeries(tss []tradier.TimeSale, n int) []float64 {
+	a := NewATR(n)
+	bs := bars(tss)
+	result := make([]float64, len(bs))
+	for i, bar := range bs {
+		result[i] = a.Update(bar)
+	}
+	return result
+}
+
+func VWAPSeries(tss []tradier.TimeSale) []float64 {
+	v := NewVWAP()
+	bs := bars(tss)
+	result := make([]float64, len(bs))
+	for i, bar := range bs {
+		result[i] = v.Update(bar)
+	}
+	return result
+}
@@ -0,0 +1,11 @@
+// Package indicators implements technical indicators over price bars.
+//
+// Each indicator is computed incrementally, one bar at a time, by calling
+// Update. The batch functions (SMASeries, EMASeries, ...) simply feed a series of
+// time sales through the same incremental implementation, so backtests
+// and live signals produce exactly the same numbers.
+//
+// Indicators return NaN until they have seen enough data. NaN inputs,
+// such as gaps in a time sales series, are ignored and the previous
+// value is returned.
+package indicators
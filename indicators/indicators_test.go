@@ -0,0 +1,181 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+var nan = math.NaN()
+
+// Ten one-minute bars, the first six on one day and the rest on the next.
+func testTimeSales() []tradier.TimeSale {
+	closes := []float64{1, 2, 3, 4, 5, 4, 3, 4, 5, 6}
+	highs := []float64{1, 0.5, 2, 1, 0.5, 1, 2, 1, 0.5, 1}
+	day1 := time.Date(2024, 1, 2, 10, 0, 0, 0, tradier.MarketLocation())
+	day2 := day1.AddDate(0, 0, 1)
+
+	tss := make([]tradier.TimeSale, len(closes))
+	for i, c := range closes {
+		t := day1.Add(time.Duration(i) * time.Minute)
+		if i >= 6 {
+			t = day2.Add(time.Duration(i-6) * time.Minute)
+		}
+		tss[i] = tradier.TimeSale{
+			Timestamp: t.Unix(),
+			Open:      tradier.FloatOrNaN(c),
+			High:      tradier.FloatOrNaN(c + highs[i]),
+			Low:       tradier.FloatOrNaN(c - 1),
+			Close:     tradier.FloatOrNaN(c),
+			Volume:    int64(100 * (i + 1)),
+		}
+	}
+	return tss
+}
+
+func assertSeries(t *testing.T, name string, got, expected []float64) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("%v: got %d values, expected %d", name, len(got), len(expected))
+	}
+	for i := range got {
+		if math.IsNaN(expected[i]) != math.IsNaN(got[i]) ||
+			(!math.IsNaN(expected[i]) && math.Abs(got[i]-expected[i]) > 1e-9) {
+			t.Errorf("%v[%d]: got %v, expected %v", name, i, got[i], expected[i])
+		}
+	}
+}
+
+// Feed each bar of the test series through an incremental indicator.
+func incremental(update func(bar tradier.Bar) float64) []float64 {
+	bars := tradier.BarsFromTimeSales("", testTimeSales())
+	result := make([]float64, len(bars))
+	for i, bar := range bars {
+		result[i] = update(bar)
+	}
+	return result
+}
+
+func onClose(update func(x float64) float64) func(bar tradier.Bar) float64 {
+	return func(bar tradier.Bar) float64 { return update(bar.Close) }
+}
+
+func TestIndicatorsMatchReferenceValues(t *testing.T) {
+	tss := testTimeSales()
+	testCases := []struct {
+		name        string
+		batch       []float64
+		incremental []float64
+		expected    []float64
+	}{
+		{
+			name:        "SMA(3)",
+			batch:       SMASeries(tss, 3),
+			incremental: incremental(onClose(NewSMA(3).Update)),
+			expected:    []float64{nan, nan, 2, 3, 4, 13.0 / 3, 4, 11.0 / 3, 4, 5},
+		},
+		{
+			// Seeded with the SMA, then smoothed by 2 / (3 + 1).
+			name:        "EMA(3)",
+			batch:       EMASeries(tss, 3),
+			incremental: incremental(onClose(NewEMA(3).Update)),
+			expected:    []float64{nan, nan, 2, 3, 4, 4, 3.5, 3.75, 4.375, 5.1875},
+		},
+		{
+			name:        "RSI(3)",
+			batch:       RSISeries(tss, 3),
+			incremental: incremental(onClose(NewRSI(3).Update)),
+			expected: []float64{nan, nan, nan, 100, 100, 200.0 / 3, 400.0 / 9,
+				62.96296296296296, 75.30864197530863, 83.53909465020575},
+		},
+		{
+			// Sample standard deviation of the last 3 log returns.
+			name:        "Volatility(3)",
+			batch:       VolatilitySeries(tss, 3, 0),
+			incremental: incremental(onClose(NewVolatility(3, 0).Update)),
+			expected: []float64{nan, nan, nan, 0.20858082853406817, 0.09244747542147815,
+				0.2781726731663289, 0.27817267316632893, 0.31521208147508795,
+				0.31521208147508795, 0.053123275198540966},
+		},
+		{
+			// True ranges are 2, 1.5, 3, 2, 1.5, 2, 3, 2, 1.5, 2.
+			name:        "ATR(3)",
+			batch:       ATRSeries(tss, 3),
+			incremental: incremental(NewATR(3).Update),
+			expected: []float64{nan, nan, 13.0 / 6, 2.111111111111111, 1.9074074074074074,
+				1.9382716049382716, 2.292181069958848, 2.1947873799725652,
+				1.963191586648377, 1.9754610577655847},
+		},
+		{
+			// Resets at the start of the second day.
+			name:        "VWAP",
+			batch:       VWAPSeries(tss),
+			incremental: incremental(NewVWAP().Update),
+			expected: []float64{1, 14.0 / 9, 22.0 / 9, 3.0666666666666664, 3.6555555555555554,
+				3.753968253968254, 10.0 / 3, 3.688888888888889, 4.118055555555556, 4.671568627450981},
+		},
+	}
+
+	for _, tc := range testCases {
+		assertSeries(t, tc.name+" batch", tc.batch, tc.expected)
+		assertSeries(t, tc.name+" incremental", tc.incremental, tc.expected)
+	}
+}
+
+func TestMACDMatchesReferenceValues(t *testing.T) {
+	batch := MACDSeries(testTimeSales(), 2, 3, 2)
+	m := NewMACD(2, 3, 2)
+	inc := incremental(func(bar tradier.Bar) float64 { return m.Update(bar.Close).MACD })
+
+	var macd, signal []float64
+	for _, v := range batch {
+		macd = append(macd, v.MACD)
+		signal = append(signal, v.Signal)
+	}
+	expectedMACD := []float64{nan, nan, 0.5, 0.5, 0.5, 1.0 / 6, -1.0 / 9,
+		0.04629629629629628, 0.22376543209876587, 0.3454218106995883}
+	assertSeries(t, "MACD batch", macd, expectedMACD)
+	assertSeries(t, "MACD incremental", inc, expectedMACD)
+	assertSeries(t, "MACD signal", signal, []float64{nan, nan, nan, 0.5, 0.5,
+		0.277777777777778, 0.01851851851851888, 0.037037037037037146,
+		0.1615226337448563, 0.28412208504801095})
+	if !m.Ready() {
+		t.Error("expected MACD to be ready")
+	}
+}
+
+func TestBollingerBandsMatchReferenceValues(t *testing.T) {
+	batch := BollingerBandsSeries(testTimeSales(), 3, 2)
+	b := NewBollingerBands(3, 2)
+	inc := incremental(func(bar tradier.Bar) float64 { return b.Update(bar.Close).Upper })
+
+	var middle, upper, lower []float64
+	for _, v := range batch {
+		middle = append(middle, v.Middle)
+		upper = append(upper, v.Upper)
+		lower = append(lower, v.Lower)
+	}
+	// The population standard deviation of 3 consecutive integers is sqrt(2/3).
+	band := 2 * math.Sqrt(2.0/3)
+	assertSeries(t, "middle", middle, []float64{nan, nan, 2, 3, 4, 13.0 / 3, 4, 11.0 / 3, 4, 5})
+	expectedUpper := []float64{nan, nan, 2 + band, 3 + band, 4 + band,
+		5.276142374915397, 4 + band, 4.60947570824873, 4 + band, 5 + band}
+	assertSeries(t, "upper batch", upper, expectedUpper)
+	assertSeries(t, "upper incremental", inc, expectedUpper)
+	assertSeries(t, "lower", lower, []float64{nan, nan, 2 - band, 3 - band, 4 - band,
+		3.3905242917512695, 4 - band, 2.7238576250846034, 4 - band, 5 - band})
+}
+
+func TestIndicatorsIgnoreNaN(t *testing.T) {
+	s := NewSMA(2)
+	s.Update(1)
+	s.Update(3)
+	if v := s.Update(nan); v != 2 {
+		t.Errorf("expected NaN input to return the previous value 2, got %v", v)
+	}
+	if v := s.Update(5); v != 4 {
+		t.Errorf("expected NaN input to be skipped, got %v", v)
+	}
+}
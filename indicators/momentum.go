@@ -0,0 +1,107 @@
+package indicators
+
+import (
+	"math"
+)
+
+// RSI is Wilder's relative strength index over N periods.
+type RSI struct {
+	gain, loss *EMA
+	prev       float64
+	value      float64
+}
+
+func NewRSI(n int) *RSI {
+	return &RSI{
+		gain:  newWilder(n),
+		loss:  newWilder(n),
+		prev:  math.NaN(),
+		value: math.NaN(),
+	}
+}
+
+func (r *RSI) Update(x float64) float64 {
+	if math.IsNaN(x) {
+		return r.value
+	}
+
+	prev := r.prev
+	r.prev = x
+	if math.IsNaN(prev) {
+		return r.value
+	}
+
+	change := x - prev
+	gain := r.gain.Update(math.Max(change, 0))
+	loss := r.loss.Update(math.Max(-change, 0))
+	if math.IsNaN(gain) || math.IsNaN(loss) {
+		return r.value
+	}
+
+	if loss == 0 {
+		if gain == 0 {
+			r.value = 50
+		} else {
+			r.value = 100
+		}
+	} else {
+		r.value = 100 - 100/(1+gain/loss)
+	}
+	return r.value
+}
+
+func (r *RSI) Value() float64 {
+	return r.value
+}
+
+func (r *RSI) Ready() bool {
+	return !math.IsNaN(r.value)
+}
+
+type MACDValue struct {
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+// MACD is the difference between a fast and slow EMA, with
+// an EMA of that difference as the signal line.
+type MACD struct {
+	fast, slow, signal *EMA
+	value              MACDValue
+}
+
+// NewMACD returns a MACD indicator. The standard parameters are 12, 26, 9.
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{
+		fast:   NewEMA(fast),
+		slow:   NewEMA(slow),
+		signal: NewEMA(signal),
+		value:  MACDValue{math.NaN(), math.NaN(), math.NaN()},
+	}
+}
+
+func (m *MACD) Update(x float64) MACDValue {
+	if math.IsNaN(x) {
+		return m.value
+	}
+
+	fast := m.fast.Update(x)
+	slow := m.slow.Update(x)
+	if math.IsNaN(fast) || math.IsNaN(slow) {
+		return m.value
+	}
+
+	m.value.MACD = fast - slow
+	m.value.Signal = m.signal.Update(m.value.MACD)
+	m.value.Histogram = m.value.MACD - m.value.Signal
+	return m.value
+}
+
+func (m *MACD) Value() MACDValue {
+	return m.value
+}
+
+func (m *MACD) Ready() bool {
+	return !math.IsNaN(m.value.Signal)
+}
@@ -0,0 +1,143 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+type BollingerValue struct {
+	Middle float64
+	Upper  float64
+	Lower  float64
+}
+
+// BollingerBands are K standard deviations above and below
+// the simple moving average of the last N values.
+type BollingerBands struct {
+	window *window
+	k      float64
+	value  BollingerValue
+}
+
+// NewBollingerBands returns Bollinger Bands. The standard parameters are 20, 2.
+func NewBollingerBands(n int, k float64) *BollingerBands {
+	return &BollingerBands{
+		window: newWindow(n),
+		k:      k,
+		value:  BollingerValue{math.NaN(), math.NaN(), math.NaN()},
+	}
+}
+
+func (b *BollingerBands) Update(x float64) BollingerValue {
+	if math.IsNaN(x) {
+		return b.value
+	}
+
+	b.window.push(x)
+	if b.window.full {
+		mean, stddev := b.window.stats()
+		b.value = BollingerValue{
+			Middle: mean,
+			Upper:  mean + b.k*stddev,
+			Lower:  mean - b.k*stddev,
+		}
+	}
+	return b.value
+}
+
+func (b *BollingerBands) Value() BollingerValue {
+	return b.value
+}
+
+func (b *BollingerBands) Ready() bool {
+	return !math.IsNaN(b.value.Middle)
+}
+
+// ATR is Wilder's average true range over N bars.
+type ATR struct {
+	avg       *EMA
+	prevClose float64
+}
+
+func NewATR(n int) *ATR {
+	return &ATR{avg: newWilder(n), prevClose: math.NaN()}
+}
+
+func (a *ATR) Update(bar tradier.Bar) float64 {
+	if math.IsNaN(bar.High) || math.IsNaN(bar.Low) || math.IsNaN(bar.Close) {
+		return a.avg.Value()
+	}
+
+	tr := bar.High - bar.Low
+	if !math.IsNaN(a.prevClose) {
+		tr = math.Max(tr, math.Abs(bar.High-a.prevClose))
+		tr = math.Max(tr, math.Abs(bar.Low-a.prevClose))
+	}
+	a.prevClose = bar.Close
+	return a.avg.Update(tr)
+}
+
+func (a *ATR) Value() float64 {
+	return a.avg.Value()
+}
+
+func (a *ATR) Ready() bool {
+	return a.avg.Ready()
+}
+
+// Volatility is the annualized standard deviation of the
+// log returns of the last N values.
+type Volatility struct {
+	window         *window
+	periodsPerYear float64
+	prev           float64
+	value          float64
+}
+
+// NewVolatility returns rolling volatility over n returns. periodsPerYear
+// annualizes the result, e.g. 252 for daily bars; if zero the volatility
+// is per period.
+func NewVolatility(n int, periodsPerYear float64) *Volatility {
+	if periodsPerYear <= 0 {
+		periodsPerYear = 1
+	}
+	return &Volatility{
+		window:         newWindow(n),
+		periodsPerYear: periodsPerYear,
+		prev:           math.NaN(),
+		value:          math.NaN(),
+	}
+}
+
+func (v *Volatility) Update(x float64) float64 {
+	if math.IsNaN(x) || x <= 0 {
+		return v.value
+	}
+
+	prev := v.prev
+	v.prev = x
+	if math.IsNaN(prev) {
+		return v.value
+	}
+
+	v.window.push(math.Log(x / prev))
+	if v.window.full {
+		// Sample standard deviation of the returns.
+		_, stddev := v.window.stats()
+		n := float64(len(v.window.values))
+		if n > 1 {
+			stddev *= math.Sqrt(n / (n - 1))
+		}
+		v.value = stddev * math.Sqrt(v.periodsPerYear)
+	}
+	return v.value
+}
+
+func (v *Volatility) Value() float64 {
+	return v.value
+}
+
+func (v *Volatility) Ready() bool {
+	return !math.IsNaN(v.value)
+}
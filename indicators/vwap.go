@@ -0,0 +1,47 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+// VWAP is the volume-weighted average of the typical price
+// (high + low + close) / 3 of each bar, reset at the start of
+// each trading day in New York time.
+type VWAP struct {
+	day         string
+	priceVolume float64
+	volume      float64
+	value       float64
+}
+
+func NewVWAP() *VWAP {
+	return &VWAP{value: math.NaN()}
+}
+
+func (v *VWAP) Update(bar tradier.Bar) float64 {
+	if day := bar.Time.In(tradier.MarketLocation()).Format("2006-01-02"); day != v.day {
+		v.day = day
+		v.priceVolume, v.volume = 0, 0
+		v.value = math.NaN()
+	}
+
+	typical := (bar.High + bar.Low + bar.Close) / 3
+	if math.IsNaN(typical) || bar.Volume <= 0 {
+		return v.value
+	}
+
+	v.priceVolume += typical * float64(bar.Volume)
+	v.volume += float64(bar.Volume)
+	v.value = v.priceVolume / v.volume
+	return v.value
+}
+
+func (v *VWAP) Value() float64 {
+	return v.value
+}
+
+func (v *VWAP) Ready() bool {
+	return !math.IsNaN(v.value)
+}
@@ -24,6 +24,12 @@ const (
 	MarketClosed     MarketState = "closed"
 )
 
+// Status of a day in the market calendar.
+const (
+	MarketDayOpen   = "open"
+	MarketDayClosed = "closed"
+)
+
 type Interval string
 
 const (
@@ -36,6 +42,15 @@ const (
 	IntervalMonthly Interval = "monthly"
 )
 
+type ExpirationType string
+
+const (
+	ExpirationStandard   ExpirationType = "standard"
+	ExpirationWeekly     ExpirationType = "weeklys"
+	ExpirationQuarterly  ExpirationType = "quarterlys"
+	ExpirationEndOfMonth ExpirationType = "eom"
+)
+
 type Filter string
 
 const (
@@ -65,9 +80,14 @@ type TradierError struct {
 	}
 	HttpStatusCode int
 	Message        string
+	// The raw body of the error response.
+	Body string `json:"-"`
 }
 
 func (te TradierError) Error() string {
+	if te.Fault.FaultString == "" && te.Message == "" && te.Body != "" {
+		return fmt.Sprintf("%d: %s", te.HttpStatusCode, te.Body)
+	}
 	return fmt.Sprintf("%d: %s - %s", te.HttpStatusCode, te.Fault.FaultString, te.Message)
 }
 
@@ -98,6 +118,14 @@ func (f *FloatOrNaN) UnmarshalJSON(data []byte) error {
 	return err
 }
 
+// MarshalJSON encodes NaN as the string "NaN", which JSON numbers cannot represent.
+func (f FloatOrNaN) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(f)) {
+		return []byte(`"NaN"`), nil
+	}
+	return json.Marshal(float64(f))
+}
+
 func (f FloatOrNaN) Value() (driver.Value, error) {
 	if math.IsNaN(float64(f)) {
 		return nil, nil
@@ -156,6 +184,40 @@ type Greeks struct {
 	SmvVol float64 `json:"smv_vol"`
 }
 
+type Expiration struct {
+	Date         DateTime
+	Type         ExpirationType
+	ContractSize int
+	Strikes      []float64
+}
+
+func (e *Expiration) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Date         DateTime
+		Type         ExpirationType `json:"expiration_type"`
+		ContractSize int            `json:"contract_size"`
+		Strikes      struct {
+			Strike floatList
+		}
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*e = Expiration{
+		Date:         raw.Date,
+		Type:         raw.Type,
+		ContractSize: raw.ContractSize,
+		Strikes:      raw.Strikes.Strike,
+	}
+	return nil
+}
+
+type OptionRoot struct {
+	RootSymbol string `json:"rootSymbol"`
+	Options    stringList
+}
+
 type TimeSale struct {
 	Date      DateTime
 	Time      DateTime
@@ -170,7 +232,8 @@ type TimeSale struct {
 }
 
 type MarketCalendar struct {
-	Date        DateTime
+	Date DateTime
+	// MarketDayOpen or MarketDayClosed.
 	Status      string
 	Description string
 	Open        struct {
@@ -189,8 +252,8 @@ type MarketCalendar struct {
 
 type MarketStatus struct {
 	Time        DateTime `json:"date"`
-	State       string
+	State       MarketState
 	Description string
-	NextChange  DateTime `json:"next_change"`
-	NextState   string   `json:"next_state"`
+	NextChange  DateTime    `json:"next_change"`
+	NextState   MarketState `json:"next_state"`
 }
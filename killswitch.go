@@ -0,0 +1,278 @@
+package tradier
+
+import (
+	"fmt"
+	"time"
+)
+
+// How often WaitForCancels checks the status of canceled orders.
+const cancelPollInterval = 500 * time.Millisecond
+
+// KillSwitchFilter restricts which orders and positions are
+// affected by CancelAllOrders and FlattenPositions.
+type KillSwitchFilter struct {
+	// If non-empty, only these symbols (or underlyings of options) are affected.
+	Symbols []string
+	// If set, only orders or positions of this class (Equity or Option) are affected.
+	// Multileg, combo and bracket orders match a class if any of their legs do.
+	Class string
+}
+
+// MatchesSymbol returns true if positions in the given symbol are selected by the filter.
+func (f KillSwitchFilter) MatchesSymbol(symbol string) bool {
+	underlying := symbol
+	class := Equity
+	if sym, err := ParseOptionSymbol(symbol); err == nil {
+		underlying = sym.Underlying()
+		class = Option
+	}
+
+	if f.Class != "" && f.Class != class {
+		return false
+	}
+	if len(f.Symbols) == 0 {
+		return true
+	}
+	return containsString(f.Symbols, symbol) || containsString(f.Symbols, underlying)
+}
+
+// MatchesOrder returns true if the given order is selected by the filter.
+func (f KillSwitchFilter) MatchesOrder(o *Order) bool {
+	if f.Class != "" && o.Class != f.Class && !orderHasClass(o, f.Class) {
+		return false
+	}
+	if len(f.Symbols) == 0 {
+		return true
+	}
+	if containsString(f.Symbols, o.Symbol) || f.MatchesSymbol(o.OptionSymbol) {
+		return true
+	}
+	for _, leg := range o.Legs {
+		if containsString(f.Symbols, leg.Symbol) || f.MatchesSymbol(leg.OptionSymbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the order, or any of its legs, trades instruments of the given class.
+func orderHasClass(o *Order, class string) bool {
+	switch o.Class {
+	case Equity, Option:
+		return o.Class == class
+	}
+	if len(o.Legs) == 0 {
+		// Legs of multileg orders may be returned without a class.
+		if o.OptionSymbol != "" || o.Class == Multileg {
+			return class == Option
+		}
+		return class == Equity && o.Symbol != ""
+	}
+	for i := range o.Legs {
+		if orderHasClass(&o.Legs[i], class) {
+			return true
+		}
+	}
+	return false
+}
+
+// CancelResult is the outcome of canceling a single order.
+type CancelResult struct {
+	Order *Order
+	Err   error
+}
+
+// FlattenResult is the outcome of closing a single position.
+type FlattenResult struct {
+	Position *Position
+	Order    Order
+	OrderId  int
+	Err      error
+}
+
+// IsOrderOpen returns true if the order may still be filled.
+func IsOrderOpen(o *Order) bool {
+	switch o.Status {
+	case Open, PartiallyFilled, Pending, Submitted:
+		return true
+	}
+	return false
+}
+
+// Cancel all open orders in the selected account that match the filter.
+// Cancellations are made concurrently; the result for each order
+// is returned, including any that failed.
+func (tc *Client) CancelAllOrders(filter KillSwitchFilter) ([]CancelResult, error) {
+	orders, err := tc.GetOpenOrders()
+	if err != nil {
+		return nil, err
+	}
+
+	var toCancel []*Order
+	for _, o := range orders {
+		if IsOrderOpen(o) && filter.MatchesOrder(o) {
+			toCancel = append(toCancel, o)
+		}
+	}
+
+	results := make([]CancelResult, len(toCancel))
+	parallelDo(len(toCancel), defaultConcurrency, func(i int) {
+		results[i] = CancelResult{
+			Order: toCancel[i],
+			Err:   tc.CancelOrder(toCancel[i].Id),
+		}
+	})
+
+	return results, nil
+}
+
+// WaitForCancels polls the orders that were successfully canceled by
+// CancelAllOrders until none of them are open, and returns an error
+// if any are still open after the timeout.
+func (tc *Client) WaitForCancels(results []CancelResult, timeout time.Duration) error {
+	var pending []int
+	for _, r := range results {
+		if r.Err == nil {
+			pending = append(pending, r.Order.Id)
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for len(pending) > 0 {
+		open := make([]bool, len(pending))
+		parallelDo(len(pending), defaultConcurrency, func(i int) {
+			o, err := tc.GetOrderStatus(pending[i])
+			if err != nil {
+				Logger.Printf("error getting status of canceled order %v: %v\n", pending[i], err)
+				open[i] = true
+				return
+			}
+			open[i] = IsOrderOpen(o)
+		})
+
+		stillPending := pending[:0]
+		for i, orderId := range pending {
+			if open[i] {
+				stillPending = append(stillPending, orderId)
+			}
+		}
+		pending = stillPending
+
+		if len(pending) > 0 {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("orders still open %v after canceling: %v", timeout, pending)
+			}
+			time.Sleep(cancelPollInterval)
+		}
+	}
+
+	return nil
+}
+
+// Close all positions in the selected account that match the filter,
+// using orders of the given type (MarketOrder or LimitOrder).
+// Limit orders are priced at the bid (for sells) or ask (for buys).
+//
+// Open orders on the positions should be canceled first with
+// CancelAllOrders, and confirmed with WaitForCancels, since Tradier
+// will reject closing orders for shares that are already committed
+// to other orders.
+func (tc *Client) FlattenPositions(filter KillSwitchFilter, orderType string) ([]FlattenResult, error) {
+	if orderType != MarketOrder && orderType != LimitOrder {
+		return nil, fmt.Errorf("unsupported order type for flattening: %v", orderType)
+	}
+
+	positions, err := tc.GetAccountPositions()
+	if err != nil {
+		return nil, err
+	}
+
+	var toClose []*Position
+	for _, p := range positions {
+		if p.Quantity != 0 && filter.MatchesSymbol(p.Symbol) {
+			toClose = append(toClose, p)
+		}
+	}
+
+	// Quotes are needed to price limit orders, and to find the
+	// underlying of option positions.
+	needQuotes := orderType == LimitOrder
+	for _, p := range toClose {
+		needQuotes = needQuotes || IsOptionSymbol(p.Symbol)
+	}
+
+	var quotes map[string]*Quote
+	if needQuotes && len(toClose) > 0 {
+		symbols := make([]string, len(toClose))
+		for i, p := range toClose {
+			symbols[i] = p.Symbol
+		}
+		quotes, err = tc.getQuotesChunked(symbols)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]FlattenResult, len(toClose))
+	parallelDo(len(toClose), defaultConcurrency, func(i int) {
+		p := toClose[i]
+		result := FlattenResult{Position: p}
+		result.Order, result.Err = ClosingOrder(p, orderType, quotes[p.Symbol])
+		if result.Err == nil {
+			result.OrderId, result.Err = tc.PlaceOrder(result.Order)
+		}
+		results[i] = result
+	})
+
+	return results, nil
+}
+
+// ClosingOrder returns the order that closes the given position.
+// A quote is required for limit orders. For options, the underlying
+// is taken from the quote if one is given, and otherwise guessed
+// from the option root.
+func ClosingOrder(p *Position, orderType string, q *Quote) (Order, error) {
+	order := Order{
+		Type:     orderType,
+		Duration: Day,
+		Quantity: p.Quantity,
+	}
+	if order.Quantity < 0 {
+		order.Quantity = -order.Quantity
+	}
+
+	if sym, err := ParseOptionSymbol(p.Symbol); err == nil {
+		order.Class = Option
+		order.Symbol = sym.Underlying()
+		if q != nil && q.Underlying != "" {
+			order.Symbol = q.Underlying
+		}
+		order.OptionSymbol = p.Symbol
+		order.Side = SellToClose
+		if p.Quantity < 0 {
+			order.Side = BuyToClose
+		}
+	} else {
+		order.Class = Equity
+		order.Symbol = p.Symbol
+		order.Side = Sell
+		if p.Quantity < 0 {
+			order.Side = BuyToCover
+		}
+	}
+
+	if orderType == LimitOrder {
+		if q == nil {
+			return order, fmt.Errorf("no quote available to price limit order for %v", p.Symbol)
+		}
+		order.Price = q.Bid
+		if SideSign(order.Side) > 0 {
+			order.Price = q.Ask
+		}
+		if order.Price <= 0 {
+			return order, fmt.Errorf("no market available to price limit order for %v", p.Symbol)
+		}
+	}
+
+	return order, nil
+}
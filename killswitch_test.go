@@ -0,0 +1,64 @@
+package tradier
+
+import (
+	"testing"
+)
+
+func TestKillSwitchFilterMatchesOrder(t *testing.T) {
+	equity := &Order{Class: Equity, Symbol: "AAPL"}
+	option := &Order{Class: Option, Symbol: "AAPL", OptionSymbol: "AAPL240621C00190000"}
+	multileg := &Order{
+		Class:  Multileg,
+		Symbol: "SPY",
+		Legs: OrderLegs{
+			{Symbol: "SPY", OptionSymbol: "SPY240621C00500000"},
+			{Symbol: "SPY", OptionSymbol: "SPY240621C00510000"},
+		},
+	}
+	combo := &Order{
+		Class:  Combo,
+		Symbol: "MSFT",
+		Legs: OrderLegs{
+			{Class: Equity, Symbol: "MSFT"},
+			{Class: Option, Symbol: "MSFT", OptionSymbol: "MSFT240621C00420000"},
+		},
+	}
+	bracket := &Order{
+		Class: OneTriggersOther,
+		Legs: OrderLegs{
+			{Class: Option, Symbol: "QQQ", OptionSymbol: "QQQ240621P00440000"},
+			{Class: Option, Symbol: "QQQ", OptionSymbol: "QQQ240621P00440000"},
+		},
+	}
+	unlegged := &Order{Class: Multileg, Symbol: "IWM"}
+
+	testCases := []struct {
+		name     string
+		filter   KillSwitchFilter
+		order    *Order
+		expected bool
+	}{
+		{"no filter", KillSwitchFilter{}, multileg, true},
+		{"equity class", KillSwitchFilter{Class: Equity}, equity, true},
+		{"equity class skips option", KillSwitchFilter{Class: Equity}, option, false},
+		{"option class", KillSwitchFilter{Class: Option}, option, true},
+		{"option class skips equity", KillSwitchFilter{Class: Option}, equity, false},
+		{"option class matches multileg", KillSwitchFilter{Class: Option}, multileg, true},
+		{"equity class skips multileg", KillSwitchFilter{Class: Equity}, multileg, false},
+		{"multileg without legs", KillSwitchFilter{Class: Option}, unlegged, true},
+		{"option class matches combo", KillSwitchFilter{Class: Option}, combo, true},
+		{"equity class matches combo", KillSwitchFilter{Class: Equity}, combo, true},
+		{"option class matches bracket", KillSwitchFilter{Class: Option}, bracket, true},
+		{"equity class skips bracket", KillSwitchFilter{Class: Equity}, bracket, false},
+		{"multileg class", KillSwitchFilter{Class: Multileg}, multileg, true},
+		{"symbol and class", KillSwitchFilter{Symbols: []string{"SPY"}, Class: Option}, multileg, true},
+		{"other symbol", KillSwitchFilter{Symbols: []string{"AAPL"}, Class: Option}, multileg, false},
+		{"leg symbol", KillSwitchFilter{Symbols: []string{"QQQ"}}, bracket, true},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.filter.MatchesOrder(tc.order); got != tc.expected {
+			t.Errorf("%v: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
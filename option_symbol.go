@@ -0,0 +1,96 @@
+package tradier
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OptionSymbol is the decoded form of an OCC option symbol,
+// e.g. SPY190621C00250000.
+type OptionSymbol struct {
+	Root       string
+	Expiration time.Time
+	OptionType string
+	Strike     float64
+}
+
+// IsOptionSymbol returns true if the given symbol looks like an OCC option symbol.
+func IsOptionSymbol(symbol string) bool {
+	_, err := ParseOptionSymbol(symbol)
+	return err == nil
+}
+
+// ParseOptionSymbol decodes an OCC option symbol into its components.
+func ParseOptionSymbol(symbol string) (OptionSymbol, error) {
+	// Root (1-6 chars) + YYMMDD + C/P + 8-digit strike (x1000).
+	if len(symbol) < 16 || len(symbol) > 21 {
+		return OptionSymbol{}, fmt.Errorf("invalid option symbol: %v", symbol)
+	}
+
+	n := len(symbol)
+	root := symbol[:n-15]
+	expiration, err := time.Parse("060102", symbol[n-15:n-9])
+	if err != nil {
+		return OptionSymbol{}, fmt.Errorf("invalid option symbol: %v", symbol)
+	}
+
+	var optionType string
+	switch symbol[n-9] {
+	case 'C':
+		optionType = Call
+	case 'P':
+		optionType = Put
+	default:
+		return OptionSymbol{}, fmt.Errorf("invalid option symbol: %v", symbol)
+	}
+
+	strike, err := strconv.ParseInt(symbol[n-8:], 10, 64)
+	if err != nil {
+		return OptionSymbol{}, fmt.Errorf("invalid option symbol: %v", symbol)
+	}
+
+	return OptionSymbol{
+		Root:       root,
+		Expiration: expiration,
+		OptionType: optionType,
+		Strike:     float64(strike) / 1000,
+	}, nil
+}
+
+// String encodes the option symbol in OCC format.
+func (o OptionSymbol) String() string {
+	t := "C"
+	if o.OptionType == Put {
+		t = "P"
+	}
+	strike := int64(o.Strike*1000 + 0.5)
+	return fmt.Sprintf("%s%s%s%08d", o.Root, o.Expiration.Format("060102"), t, strike)
+}
+
+// Option roots that differ from the symbol of their underlying.
+var optionRootUnderlyings = map[string]string{
+	"SPXW":  "SPX",
+	"SPXPM": "SPX",
+	"NDXP":  "NDX",
+	"RUTW":  "RUT",
+	"VIXW":  "VIX",
+	"DJXW":  "DJX",
+}
+
+// Underlying returns the symbol of the underlying implied by the root,
+// mapping weekly and PM-settled index roots (e.g. SPXW) to their index
+// and stripping the digit suffix of adjusted roots (e.g. AAPL1).
+// This is a best guess; the Underlying of the option's quote is
+// authoritative when available.
+func (o OptionSymbol) Underlying() string {
+	if underlying, ok := optionRootUnderlyings[o.Root]; ok {
+		return underlying
+	}
+	root := strings.TrimRight(o.Root, "0123456789")
+	if root == "" {
+		return o.Root
+	}
+	return root
+}
@@ -0,0 +1,650 @@
+package tradier
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PaperBrokerParams configures a PaperBroker.
+type PaperBrokerParams struct {
+	// Starting cash balance.
+	InitialCash float64
+	// Commission charged per order, and additionally per option contract.
+	CommissionPerOrder    float64
+	CommissionPerContract float64
+	// Whether the account may go short or borrow cash. If false, orders
+	// that would make cash or a position negative are rejected.
+	AllowMargin bool
+	// If set, the simulator state is persisted to this file after
+	// every change and restored from it on creation.
+	StatePath string
+	// Source of the current time. Defaults to time.Now; a backtest can
+	// substitute simulated time.
+	Clock func() time.Time
+	// Orders may not fill until this long after they are placed or changed.
+	Latency time.Duration
+	// Fractional amount by which fills are worse than the quote,
+	// i.e. 0.001 buys 0.1% above the ask.
+	Slippage float64
+}
+
+// PaperBroker is a simulated broker that fills orders against market
+// quotes and tracks cash and positions locally. Quotes are fed to it from
+// the market stream with HandleQuote/HandleTrade (e.g. via Demuxer),
+// or fetched with RefreshQuotes.
+//
+// Orders fill in full at the bid or ask once their price is marketable.
+type PaperBroker struct {
+	params PaperBrokerParams
+
+	mu     sync.Mutex
+	state  paperState
+	quotes map[string]*Quote
+}
+
+// The persisted state of a PaperBroker.
+type paperState struct {
+	Cash    float64
+	ClosePL float64
+	// Market date (in America/New_York) that ClosePL is for.
+	ClosePLDate    string
+	NextId         int
+	NextPositionId int
+	Orders         []*Order
+	Positions      map[string]*Position
+}
+
+func NewPaperBroker(params PaperBrokerParams) (*PaperBroker, error) {
+	if params.Clock == nil {
+		params.Clock = time.Now
+	}
+
+	pb := &PaperBroker{
+		params: params,
+		state: paperState{
+			Cash:           params.InitialCash,
+			NextId:         1,
+			NextPositionId: 1,
+			Positions:      make(map[string]*Position),
+		},
+		quotes: make(map[string]*Quote),
+	}
+
+	if params.StatePath != "" {
+		err := readJSONFile(params.StatePath, &pb.state)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if pb.state.Positions == nil {
+			pb.state.Positions = make(map[string]*Position)
+		}
+		// State saved before position ids were tracked.
+		for _, p := range pb.state.Positions {
+			if p.Id >= pb.state.NextPositionId {
+				pb.state.NextPositionId = p.Id + 1
+			}
+		}
+	}
+
+	return pb, nil
+}
+
+func (pb *PaperBroker) PlaceOrder(order Order) (int, error) {
+	if _, err := orderToParams(order); err != nil {
+		return 0, err
+	}
+	switch order.Class {
+	case Equity, Option, Multileg, Combo:
+	default:
+		return 0, fmt.Errorf("order class %v is not supported by the paper broker", order.Class)
+	}
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	now := pb.params.Clock()
+	order.Id = pb.state.NextId
+	pb.state.NextId++
+	order.Status = Open
+	order.RemainingQuantity = order.Quantity
+	order.ExecutedQuantity = 0
+	order.CreateDate = DateTime{now}
+	order.TransactionDate = DateTime{now}
+	if order.Class == Option && order.Symbol == "" {
+		if sym, err := ParseOptionSymbol(order.OptionSymbol); err == nil {
+			order.Symbol = sym.Underlying()
+		}
+	}
+	pb.state.Orders = append(pb.state.Orders, &order)
+
+	pb.tryFill(&order)
+	return order.Id, pb.save()
+}
+
+func (pb *PaperBroker) ChangeOrder(orderId int, order Order) error {
+	if _, err := updateOrderParams(order); err != nil {
+		return err
+	}
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	existing := pb.findOrder(orderId)
+	if existing == nil {
+		return fmt.Errorf("unknown order: %v", orderId)
+	} else if !IsOrderOpen(existing) {
+		return fmt.Errorf("cannot change order %v with status %v", orderId, existing.Status)
+	}
+
+	existing.Type = order.Type
+	existing.Duration = order.Duration
+	existing.Price = order.Price
+	existing.StopPrice = order.StopPrice
+	existing.TransactionDate = DateTime{pb.params.Clock()}
+	pb.tryFill(existing)
+	return pb.save()
+}
+
+func (pb *PaperBroker) CancelOrder(orderId int) error {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	existing := pb.findOrder(orderId)
+	if existing == nil {
+		return fmt.Errorf("unknown order: %v", orderId)
+	} else if !IsOrderOpen(existing) {
+		return fmt.Errorf("cannot cancel order %v with status %v", orderId, existing.Status)
+	}
+
+	existing.Status = Canceled
+	existing.TransactionDate = DateTime{pb.params.Clock()}
+	return pb.save()
+}
+
+// GetOpenOrders returns all orders placed with the broker, like Tradier's
+// orders endpoint does. Use IsOrderOpen to select working orders.
+func (pb *PaperBroker) GetOpenOrders() ([]*Order, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	result := make([]*Order, len(pb.state.Orders))
+	for i, o := range pb.state.Orders {
+		order := *o
+		result[i] = &order
+	}
+	return result, nil
+}
+
+func (pb *PaperBroker) GetOrderStatus(orderId int) (*Order, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	existing := pb.findOrder(orderId)
+	if existing == nil {
+		return nil, fmt.Errorf("unknown order: %v", orderId)
+	}
+	order := *existing
+	return &order, nil
+}
+
+func (pb *PaperBroker) GetAccountPositions() ([]*Position, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.positions(), nil
+}
+
+func (pb *PaperBroker) GetAccountBalances() (*AccountBalances, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.rollClosePL()
+
+	b := &AccountBalances{
+		AccountNumber: "paper",
+		AccountType:   AccountTypeCash,
+		ClosePL:       pb.state.ClosePL,
+		TotalCash:     pb.state.Cash,
+	}
+	if pb.params.AllowMargin {
+		b.AccountType = AccountTypeMargin
+	}
+
+	var costBasis float64
+	for _, p := range pb.state.Positions {
+		value := p.CostBasis
+		if q, ok := pb.quotes[p.Symbol]; ok {
+			value = paperMark(q) * p.Quantity * contractMultiplier(p.Symbol)
+		}
+		costBasis += p.CostBasis
+		b.MarketValue += value
+		if value >= 0 {
+			b.LongMarketValue += value
+		} else {
+			b.ShortMarketValue += value
+		}
+		if IsOptionSymbol(p.Symbol) {
+			if value >= 0 {
+				b.OptionLongValue += value
+			} else {
+				b.OptionShortValue += value
+			}
+		} else if value >= 0 {
+			b.StockLongValue += value
+		}
+	}
+	for _, o := range pb.state.Orders {
+		if IsOrderOpen(o) {
+			b.PendingOrdersCount++
+		}
+	}
+
+	b.OpenPL = b.MarketValue - costBasis
+	b.Equity = b.TotalCash + b.MarketValue
+	b.TotalEquity = b.Equity
+	b.Cash.CashAvailable = b.TotalCash
+	b.Margin.StockBuyingPower = b.TotalCash
+	b.Margin.OptionBuyingPower = b.TotalCash
+	return b, nil
+}
+
+// Demuxer returns a StreamDemuxer that feeds quotes and trades to the broker.
+func (pb *PaperBroker) Demuxer() *StreamDemuxer {
+	return &StreamDemuxer{
+		Quotes: pb.HandleQuote,
+		Trades: pb.HandleTrade,
+		Errors: func(err error) { Logger.Println(err) },
+	}
+}
+
+// HandleQuote updates the market for the quoted symbol and fills any
+// orders that have become marketable.
+func (pb *PaperBroker) HandleQuote(q *QuoteEvent) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	quote := pb.quote(q.Symbol)
+	quote.Bid, quote.Ask = q.Bid, q.Ask
+	quote.BidSize, quote.AskSize = int(q.BidSize), int(q.AskSize)
+	pb.fillOpenOrders(map[string]bool{q.Symbol: true})
+}
+
+// HandleTrade updates the last price of the traded symbol and fills any
+// orders that have become marketable.
+func (pb *PaperBroker) HandleTrade(t *TradeEvent) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	quote := pb.quote(t.Symbol)
+	quote.Last = t.Price
+	pb.fillOpenOrders(map[string]bool{t.Symbol: true})
+}
+
+// SetQuotes replaces the market for the given quotes' symbols (e.g. from
+// GetQuotes) and fills any orders in those symbols that have become
+// marketable.
+func (pb *PaperBroker) SetQuotes(quotes []*Quote) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	updated := make(map[string]bool, len(quotes))
+	for _, q := range quotes {
+		quote := *q
+		pb.quotes[q.Symbol] = &quote
+		updated[q.Symbol] = true
+	}
+	pb.fillOpenOrders(updated)
+}
+
+// RefreshQuotes fetches quotes with the given client for every symbol
+// with a position or open order, and updates the market with them.
+func (pb *PaperBroker) RefreshQuotes(client *Client) error {
+	pb.mu.Lock()
+	seen := make(map[string]bool)
+	var symbols []string
+	add := func(symbol string) {
+		if symbol != "" && !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	for symbol := range pb.state.Positions {
+		add(symbol)
+	}
+	for _, o := range pb.state.Orders {
+		if IsOrderOpen(o) {
+			for _, leg := range orderLegs(*o) {
+				add(legSymbol(leg))
+			}
+		}
+	}
+	pb.mu.Unlock()
+
+	if len(symbols) == 0 {
+		return nil
+	}
+	quotes, err := client.getQuotesChunked(symbols)
+	if err != nil {
+		return err
+	}
+	list := make([]*Quote, 0, len(quotes))
+	for _, q := range quotes {
+		list = append(list, q)
+	}
+	pb.SetQuotes(list)
+	return nil
+}
+
+func (pb *PaperBroker) quote(symbol string) *Quote {
+	q, ok := pb.quotes[symbol]
+	if !ok {
+		q = &Quote{Symbol: symbol}
+		pb.quotes[symbol] = q
+	}
+	return q
+}
+
+func (pb *PaperBroker) findOrder(orderId int) *Order {
+	for _, o := range pb.state.Orders {
+		if o.Id == orderId {
+			return o
+		}
+	}
+	return nil
+}
+
+func (pb *PaperBroker) positions() []*Position {
+	result := make([]*Position, 0, len(pb.state.Positions))
+	for _, p := range pb.state.Positions {
+		position := *p
+		result = append(result, &position)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Symbol < result[j].Symbol })
+	return result
+}
+
+// Fill open orders with a leg in one of the updated symbols. Orders are
+// only filled when their own market changes, so that they never fill at
+// a stale quote just because another symbol was quoted.
+func (pb *PaperBroker) fillOpenOrders(updated map[string]bool) {
+	filled := false
+	for _, o := range pb.state.Orders {
+		if IsOrderOpen(o) && orderHasSymbol(*o, updated) {
+			filled = pb.tryFill(o) || filled
+		}
+	}
+	if filled {
+		if err := pb.save(); err != nil {
+			Logger.Println(err)
+		}
+	}
+}
+
+func orderHasSymbol(o Order, symbols map[string]bool) bool {
+	for _, leg := range orderLegs(o) {
+		if symbols[legSymbol(leg)] {
+			return true
+		}
+	}
+	return false
+}
+
+type paperFill struct {
+	symbol   string
+	side     string
+	quantity float64
+	price    float64
+}
+
+// Fill the order if it is marketable at the current quotes.
+// Returns true if the order filled or was rejected.
+func (pb *PaperBroker) tryFill(o *Order) bool {
+	if pb.params.Clock().Before(o.TransactionDate.Add(pb.params.Latency)) {
+		return false
+	}
+
+	var fills []paperFill
+	var avgPrice float64
+	switch o.Class {
+	case Equity, Option:
+		price, ok := pb.fillPrice(o)
+		if !ok {
+			return false
+		}
+		fills = []paperFill{{legSymbol(*o), o.Side, o.Quantity, price}}
+		avgPrice = price
+	case Multileg, Combo:
+		var ok bool
+		fills, avgPrice, ok = pb.multilegFills(o)
+		if !ok {
+			return false
+		}
+	}
+
+	if reason := pb.checkFills(fills); reason != "" {
+		o.Status = Rejected
+		o.TransactionDate = DateTime{pb.params.Clock()}
+		Logger.Printf("paper broker rejected order %v: %v\n", o.Id, reason)
+		return true
+	}
+
+	commission := pb.params.CommissionPerOrder
+	for _, f := range fills {
+		if IsOptionSymbol(f.symbol) {
+			commission += pb.params.CommissionPerContract * f.quantity
+		}
+		pb.applyFill(f)
+	}
+	pb.state.Cash -= commission
+
+	for i := range o.Legs {
+		o.Legs[i].Status = Filled
+		o.Legs[i].ExecutedQuantity = fills[i].quantity
+		o.Legs[i].AverageFillPrice = fills[i].price
+	}
+	o.Status = Filled
+	o.ExecutedQuantity = o.Quantity
+	o.RemainingQuantity = 0
+	o.AverageFillPrice = avgPrice
+	o.LastFillPrice = avgPrice
+	o.LastFillQuantity = o.Quantity
+	o.TransactionDate = DateTime{pb.params.Clock()}
+	return true
+}
+
+// The price at which a single-leg order fills at the current quote, if it is marketable.
+func (pb *PaperBroker) fillPrice(o *Order) (float64, bool) {
+	q, ok := pb.quotes[legSymbol(*o)]
+	if !ok {
+		return 0, false
+	}
+
+	buy := SideSign(o.Side) > 0
+	price, ok := quotePrice(q, buy)
+	if !ok {
+		return 0, false
+	}
+
+	if o.Type == StopOrder || o.Type == StopLimitOrder {
+		triggered := (buy && price >= o.StopPrice) || (!buy && price <= o.StopPrice)
+		if !triggered {
+			return 0, false
+		}
+	}
+
+	if o.Type == LimitOrder || o.Type == StopLimitOrder {
+		if (buy && price > o.Price) || (!buy && price < o.Price) {
+			return 0, false
+		}
+		// Limit orders may slip, but never through their limit.
+		if buy {
+			return math.Min(pb.slip(price, buy), o.Price), true
+		}
+		return math.Max(pb.slip(price, buy), o.Price), true
+	}
+
+	return pb.slip(price, buy), true
+}
+
+// The price a buy or sell trades at: the ask or bid, or the last
+// price if that side of the market is empty.
+func quotePrice(q *Quote, buy bool) (float64, bool) {
+	price := q.Bid
+	if buy {
+		price = q.Ask
+	}
+	if price <= 0 {
+		price = q.Last
+	}
+	return price, price > 0
+}
+
+// Apply slippage to a fill price.
+func (pb *PaperBroker) slip(price float64, buy bool) float64 {
+	if buy {
+		return price * (1 + pb.params.Slippage)
+	}
+	return price * (1 - pb.params.Slippage)
+}
+
+func (pb *PaperBroker) multilegFills(o *Order) ([]paperFill, float64, bool) {
+	// Each leg fills at the bid or ask with slippage, like single-leg orders,
+	// and the order is marketable if the resulting net price is.
+	unit := strategyUnit(o.Legs)
+	fills := make([]paperFill, 0, len(o.Legs))
+	var net float64
+	for _, leg := range o.Legs {
+		q, ok := pb.quotes[legSymbol(leg)]
+		if !ok {
+			return nil, 0, false
+		}
+		buy := SideSign(leg.Side) > 0
+		legPrice, ok := quotePrice(q, buy)
+		if !ok {
+			return nil, 0, false
+		}
+		legPrice = pb.slip(legPrice, buy)
+		fills = append(fills, paperFill{legSymbol(leg), leg.Side, leg.Quantity, legPrice})
+
+		// Net price per unit of the strategy, as in PriceStrategy.
+		ratio := leg.Quantity / unit
+		if leg.OptionSymbol == "" {
+			ratio = leg.Quantity / defaultContractSize / unit
+		}
+		net += SideSign(leg.Side) * ratio * legPrice
+	}
+
+	switch o.Type {
+	case Debit:
+		if net > o.Price {
+			return nil, 0, false
+		}
+	case Credit:
+		if -net < o.Price {
+			return nil, 0, false
+		}
+	case Even:
+		if net > 0 {
+			return nil, 0, false
+		}
+	}
+
+	// Order quantity for multileg orders is the number of strategy units.
+	if o.Quantity == 0 {
+		o.Quantity = unit
+	}
+	return fills, math.Abs(net), true
+}
+
+// Check that the fills are allowed without margin. Returns the reason if not.
+func (pb *PaperBroker) checkFills(fills []paperFill) string {
+	if pb.params.AllowMargin {
+		return ""
+	}
+
+	cash := pb.state.Cash
+	for _, f := range fills {
+		sign := SideSign(f.side)
+		cash -= sign * f.price * f.quantity * contractMultiplier(f.symbol)
+		var current float64
+		if p, ok := pb.state.Positions[f.symbol]; ok {
+			current = p.Quantity
+		}
+		if current+sign*f.quantity < 0 {
+			return fmt.Sprintf("short position in %v requires margin", f.symbol)
+		}
+	}
+	if cash < 0 {
+		return fmt.Sprintf("insufficient cash: would leave $%.2f", cash)
+	}
+	return ""
+}
+
+// Apply a fill to cash and positions.
+func (pb *PaperBroker) applyFill(f paperFill) {
+	multiplier := contractMultiplier(f.symbol)
+	delta := SideSign(f.side) * f.quantity
+	pb.state.Cash -= delta * f.price * multiplier
+
+	p, ok := pb.state.Positions[f.symbol]
+	if !ok {
+		p = &Position{
+			Symbol:       f.symbol,
+			DateAcquired: DateTime{pb.params.Clock()},
+			Id:           pb.state.NextPositionId,
+		}
+		pb.state.NextPositionId++
+		pb.state.Positions[f.symbol] = p
+	}
+
+	if p.Quantity == 0 || (p.Quantity > 0) == (delta > 0) {
+		// Opening or adding to a position.
+		p.Quantity += delta
+		p.CostBasis += delta * f.price * multiplier
+		return
+	}
+
+	// Reducing (and possibly reversing) a position.
+	closed := math.Min(math.Abs(delta), math.Abs(p.Quantity))
+	closedCost := p.CostBasis * closed / math.Abs(p.Quantity)
+	proceeds := math.Copysign(closed, p.Quantity) * f.price * multiplier
+	pb.rollClosePL()
+	pb.state.ClosePL += proceeds - closedCost
+	p.CostBasis -= closedCost
+	p.Quantity += delta
+
+	if p.Quantity == 0 {
+		delete(pb.state.Positions, f.symbol)
+	} else if (p.Quantity > 0) == (delta > 0) {
+		// Reversed through zero: the remainder is a new position.
+		p.CostBasis = p.Quantity * f.price * multiplier
+		p.DateAcquired = DateTime{pb.params.Clock()}
+	}
+}
+
+// Reset the closed P&L at the start of each market day, as Tradier does.
+// Must be called with pb.mu held.
+func (pb *PaperBroker) rollClosePL() {
+	today := pb.params.Clock().In(MarketLocation()).Format("2006-01-02")
+	if pb.state.ClosePLDate != today {
+		pb.state.ClosePL = 0
+		pb.state.ClosePLDate = today
+	}
+}
+
+// Persist the simulator state. Must be called with pb.mu held.
+func (pb *PaperBroker) save() error {
+	if pb.params.StatePath == "" {
+		return nil
+	}
+	return writeJSONFile(pb.params.StatePath, &pb.state)
+}
+
+// The mark used to value positions: mid if available, otherwise last.
+func paperMark(q *Quote) float64 {
+	if mark := QuoteMark(q); mark > 0 {
+		return mark
+	}
+	return q.Last
+}
+
+func contractMultiplier(symbol string) float64 {
+	if IsOptionSymbol(symbol) {
+		return defaultContractSize
+	}
+	return 1
+}
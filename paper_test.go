@@ -0,0 +1,166 @@
+package tradier
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestPaperBroker(t *testing.T, params PaperBrokerParams) *PaperBroker {
+	pb, err := NewPaperBroker(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pb
+}
+
+func placeTestOrder(t *testing.T, pb *PaperBroker, order Order) *Order {
+	t.Helper()
+	id, err := pb.PlaceOrder(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := pb.GetOrderStatus(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func equityOrder(symbol, side string, quantity float64) Order {
+	return Order{
+		Class:    Equity,
+		Symbol:   symbol,
+		Side:     side,
+		Quantity: quantity,
+		Type:     MarketOrder,
+		Duration: Day,
+	}
+}
+
+func TestPaperBrokerFillWithSlippage(t *testing.T) {
+	pb := newTestPaperBroker(t, PaperBrokerParams{
+		InitialCash:        10000,
+		CommissionPerOrder: 1,
+		Slippage:           0.01,
+	})
+	pb.SetQuotes([]*Quote{{Symbol: "XYZ", Bid: 99, Ask: 100}})
+
+	o := placeTestOrder(t, pb, equityOrder("XYZ", Buy, 10))
+	if o.Status != Filled {
+		t.Fatalf("expected market order to fill, got %v", o.Status)
+	}
+	assertFloat(t, "fill price", o.AverageFillPrice, 101)
+
+	limit := equityOrder("XYZ", Buy, 10)
+	limit.Type, limit.Price = LimitOrder, 100.5
+	o = placeTestOrder(t, pb, limit)
+	assertFloat(t, "limit fill price", o.AverageFillPrice, 100.5)
+
+	limit.Price = 99.5
+	if o = placeTestOrder(t, pb, limit); o.Status != Open {
+		t.Errorf("expected limit below the ask to rest, got %v", o.Status)
+	}
+
+	b, err := pb.GetAccountBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFloat(t, "cash", b.TotalCash, 10000-1010-1005-2)
+	assertFloat(t, "market value", b.MarketValue, 20*99.5)
+}
+
+func TestPaperBrokerMultilegEmptySide(t *testing.T) {
+	order := NewVerticalSpread("SPY", testNear, Call, 500, 510, 1)
+	long, short := order.Legs[0].OptionSymbol, order.Legs[1].OptionSymbol
+
+	// The short leg requires margin.
+	pb := newTestPaperBroker(t, PaperBrokerParams{InitialCash: 10000, AllowMargin: true})
+	pb.SetQuotes([]*Quote{
+		{Symbol: long},
+		{Symbol: short, Bid: 2, Ask: 2.2},
+	})
+	if o := placeTestOrder(t, pb, order); o.Status != Open {
+		t.Fatalf("expected order not to fill without a price for the long leg, got %v", o.Status)
+	}
+
+	// The long leg has no ask, so it fills at the last price.
+	pb.SetQuotes([]*Quote{{Symbol: long, Bid: 4.8, Last: 5}})
+	o, err := pb.GetOrderStatus(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != Filled {
+		t.Fatalf("expected order to fill, got %v", o.Status)
+	}
+	assertFloat(t, "net price", o.AverageFillPrice, 3)
+	assertFloat(t, "long leg price", o.Legs[0].AverageFillPrice, 5)
+	assertFloat(t, "short leg price", o.Legs[1].AverageFillPrice, 2)
+
+	b, err := pb.GetAccountBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFloat(t, "cash", b.TotalCash, 10000-300)
+}
+
+func TestPaperBrokerMargin(t *testing.T) {
+	quotes := []*Quote{{Symbol: "XYZ", Bid: 100, Ask: 100}}
+
+	pb := newTestPaperBroker(t, PaperBrokerParams{InitialCash: 10000})
+	pb.SetQuotes(quotes)
+	if o := placeTestOrder(t, pb, equityOrder("XYZ", SellShort, 10)); o.Status != Rejected {
+		t.Errorf("expected short sale to be rejected without margin, got %v", o.Status)
+	}
+	if o := placeTestOrder(t, pb, equityOrder("XYZ", Buy, 101)); o.Status != Rejected {
+		t.Errorf("expected purchase exceeding cash to be rejected, got %v", o.Status)
+	}
+
+	pb = newTestPaperBroker(t, PaperBrokerParams{InitialCash: 10000, AllowMargin: true})
+	pb.SetQuotes(quotes)
+	if o := placeTestOrder(t, pb, equityOrder("XYZ", SellShort, 10)); o.Status != Filled {
+		t.Errorf("expected short sale to fill with margin, got %v", o.Status)
+	}
+	positions, err := pb.GetAccountPositions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 1 || positions[0].Quantity != -10 {
+		t.Errorf("expected short position of 10, got %v", positions)
+	}
+}
+
+func TestPaperBrokerClosePL(t *testing.T) {
+	now := time.Date(2024, 3, 4, 10, 0, 0, 0, MarketLocation())
+	pb := newTestPaperBroker(t, PaperBrokerParams{
+		InitialCash: 10000,
+		Clock:       func() time.Time { return now },
+	})
+
+	pb.SetQuotes([]*Quote{{Symbol: "XYZ", Bid: 100, Ask: 100}})
+	placeTestOrder(t, pb, equityOrder("XYZ", Buy, 10))
+	pb.SetQuotes([]*Quote{{Symbol: "XYZ", Bid: 110, Ask: 110}})
+	placeTestOrder(t, pb, equityOrder("XYZ", Sell, 10))
+
+	b, err := pb.GetAccountBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFloat(t, "close P&L", b.ClosePL, 100)
+
+	now = now.AddDate(0, 0, 1)
+	b, err = pb.GetAccountBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFloat(t, "close P&L on the next day", b.ClosePL, 0)
+
+	// Position ids are not reused after a position is closed.
+	placeTestOrder(t, pb, equityOrder("XYZ", Buy, 1))
+	positions, err := pb.GetAccountPositions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 1 || positions[0].Id != 2 {
+		t.Errorf("expected new position with id 2, got %v", positions)
+	}
+}
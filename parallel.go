@@ -0,0 +1,38 @@
+package tradier
+
+import (
+	"sync"
+)
+
+// Default number of concurrent requests made by methods that fan out
+// over many symbols or orders. This is kept small so that we stay well
+// within Tradier's rate limits; quota violations are retried by do().
+const defaultConcurrency = 4
+
+// Call fn(i) for i in [0, n) using at most the given number of workers.
+func parallelDo(n, workers int, fn func(i int)) {
+	if workers <= 0 {
+		workers = defaultConcurrency
+	}
+	if workers > n {
+		workers = n
+	}
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+}
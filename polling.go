@@ -0,0 +1,149 @@
+package tradier
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PollingQuoteFeed polls quotes for a set of symbols and emits synthetic
+// quote, trade and summary events whenever they change, in the same form
+// as the market events stream. It can be used in place of
+// StreamMarketEvents where streaming is not available, such as in the
+// sandbox or with delayed data.
+type PollingQuoteFeed struct {
+	client   *Client
+	symbols  []string
+	interval time.Duration
+
+	prev      map[string]*Quote
+	closeChan chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPollingQuoteFeed starts polling quotes every interval and sends
+// events to output, which is closed when the feed is stopped.
+func NewPollingQuoteFeed(client *Client, symbols []string, interval time.Duration,
+	output chan *StreamEvent) *PollingQuoteFeed {
+	pqf := &PollingQuoteFeed{
+		client:    client,
+		symbols:   symbols,
+		interval:  interval,
+		prev:      make(map[string]*Quote),
+		closeChan: make(chan struct{}),
+	}
+	go pqf.poll(output)
+	return pqf
+}
+
+func (pqf *PollingQuoteFeed) Stop() {
+	pqf.closeOnce.Do(func() { close(pqf.closeChan) })
+}
+
+func (pqf *PollingQuoteFeed) poll(output chan *StreamEvent) {
+	defer close(output)
+
+	ticker := time.NewTicker(pqf.interval)
+	defer ticker.Stop()
+	reportedUnmatched := false
+	for {
+		batch, err := pqf.client.GetQuotesBatch(pqf.symbols, QuoteBatchOptions{NoGreeks: true})
+		if err != nil {
+			Logger.Println(err)
+		} else {
+			if len(batch.Unmatched) > 0 && !reportedUnmatched {
+				Logger.Printf("polling quote feed: unknown symbols %v\n", batch.Unmatched)
+				reportedUnmatched = true
+			}
+
+			for _, q := range batch.Quotes {
+				for _, event := range pqf.diff(q) {
+					select {
+					case output <- event:
+					case <-pqf.closeChan:
+						return
+					default:
+						Logger.Println("polling output channel is full, dropping stream event")
+					}
+				}
+				pqf.prev[q.Symbol] = q
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-pqf.closeChan:
+			return
+		}
+	}
+}
+
+// Return events for whatever changed since the previous quote for the symbol.
+func (pqf *PollingQuoteFeed) diff(q *Quote) []*StreamEvent {
+	prev, ok := pqf.prev[q.Symbol]
+	if !ok {
+		prev = &Quote{}
+	}
+
+	var events []*StreamEvent
+	if q.Open != prev.Open || q.High != prev.High || q.Low != prev.Low || q.PreviousClose != prev.PreviousClose {
+		events = append(events, syntheticEvent("summary", q.Symbol, map[string]interface{}{
+			"open":      formatFloat(q.Open),
+			"high":      formatFloat(q.High),
+			"low":       formatFloat(q.Low),
+			"prevClose": formatFloat(q.PreviousClose),
+		}))
+	}
+	if q.Last > 0 && (q.Last != prev.Last || q.Volume != prev.Volume || !q.TradeDate.Equal(prev.TradeDate.Time)) {
+		events = append(events, syntheticEvent("trade", q.Symbol, map[string]interface{}{
+			"exch":  q.Exchange,
+			"price": formatFloat(q.Last),
+			"last":  formatFloat(q.Last),
+			"size":  strconv.Itoa(q.LastVolume),
+			"cvol":  strconv.Itoa(q.Volume),
+			"date":  formatMs(q.TradeDate.Time),
+		}))
+	}
+	if q.Bid != prev.Bid || q.Ask != prev.Ask || q.BidSize != prev.BidSize || q.AskSize != prev.AskSize {
+		events = append(events, syntheticEvent("quote", q.Symbol, map[string]interface{}{
+			"bid":     q.Bid,
+			"bidsz":   q.BidSize,
+			"bidexch": q.BidExchange,
+			"biddate": formatMs(q.BidDate.Time),
+			"ask":     q.Ask,
+			"asksz":   q.AskSize,
+			"askexch": q.AskExchange,
+			"askdate": formatMs(q.AskDate.Time),
+		}))
+	}
+
+	return events
+}
+
+// Encode an event in the same form as the market events stream, so that
+// it is decoded by exactly the same code.
+func syntheticEvent(eventType, symbol string, fields map[string]interface{}) *StreamEvent {
+	fields["type"] = eventType
+	fields["symbol"] = symbol
+
+	event := &StreamEvent{}
+	buf, err := json.Marshal(fields)
+	if err != nil {
+		event.Error = err
+		return event
+	}
+	UnmarshalStreamEvent(buf, event)
+	return event
+}
+
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+func formatMs(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
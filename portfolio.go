@@ -0,0 +1,146 @@
+package tradier
+
+import (
+	"math"
+	"time"
+)
+
+// Default number of shares per option contract.
+const defaultContractSize = 100
+
+// PortfolioPosition is a Position joined with its current market quote.
+type PortfolioPosition struct {
+	Position
+	Quote *Quote
+
+	// Mark is the mid of the current bid/ask if available,
+	// otherwise the last trade price.
+	Mark                float64
+	Multiplier          float64
+	MarketValue         float64
+	UnrealizedPL        float64
+	UnrealizedPLPercent float64
+	DayChange           float64
+
+	// Only set for option positions.
+	Underlying string
+	Greeks     *Greeks
+}
+
+// IsOption returns true if the position is in an option contract.
+func (pp *PortfolioPosition) IsOption() bool {
+	return pp.Underlying != ""
+}
+
+// Portfolio is the set of positions in an account, valued at current marks.
+type Portfolio struct {
+	Positions    []*PortfolioPosition
+	CostBasis    float64
+	MarketValue  float64
+	UnrealizedPL float64
+	DayChange    float64
+}
+
+// Get the positions in the selected account, enriched with current
+// quotes, market values and unrealized P&L.
+func (tc *Client) GetPortfolio() (*Portfolio, error) {
+	positions, err := tc.GetAccountPositions()
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, len(positions))
+	for i, p := range positions {
+		symbols[i] = p.Symbol
+	}
+	quotes, err := tc.getQuotesChunked(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPortfolio(positions, quotes, time.Now()), nil
+}
+
+// NewPortfolio joins the given positions with quotes (keyed by symbol).
+// Positions without a quote are valued at their cost basis.
+func NewPortfolio(positions []*Position, quotes map[string]*Quote, now time.Time) *Portfolio {
+	portfolio := &Portfolio{
+		Positions: make([]*PortfolioPosition, 0, len(positions)),
+	}
+
+	for _, p := range positions {
+		pp := newPortfolioPosition(p, quotes[p.Symbol], now)
+		portfolio.Positions = append(portfolio.Positions, pp)
+		portfolio.CostBasis += pp.CostBasis
+		portfolio.MarketValue += pp.MarketValue
+		portfolio.UnrealizedPL += pp.UnrealizedPL
+		portfolio.DayChange += pp.DayChange
+	}
+
+	return portfolio
+}
+
+func newPortfolioPosition(p *Position, q *Quote, now time.Time) *PortfolioPosition {
+	pp := &PortfolioPosition{
+		Position:   *p,
+		Quote:      q,
+		Multiplier: 1,
+	}
+
+	if sym, err := ParseOptionSymbol(p.Symbol); err == nil {
+		pp.Underlying = sym.Underlying()
+		pp.Multiplier = defaultContractSize
+	}
+
+	if q == nil {
+		pp.MarketValue = p.CostBasis
+		return pp
+	}
+
+	if q.Type == Option {
+		if q.Underlying != "" {
+			pp.Underlying = q.Underlying
+		}
+		if q.ContractSize > 0 {
+			pp.Multiplier = float64(q.ContractSize)
+		}
+		greeks := q.Greeks
+		pp.Greeks = &greeks
+	}
+
+	pp.Mark = QuoteMark(q)
+	pp.MarketValue = pp.Mark * p.Quantity * pp.Multiplier
+	pp.UnrealizedPL = pp.MarketValue - p.CostBasis
+	if p.CostBasis != 0 {
+		pp.UnrealizedPLPercent = 100 * pp.UnrealizedPL / math.Abs(p.CostBasis)
+	}
+
+	if acquiredOn(p.DateAcquired.Time, now) {
+		// Position was opened today, so the whole P&L is today's change.
+		pp.DayChange = pp.UnrealizedPL
+	} else if q.PreviousClose > 0 {
+		pp.DayChange = (pp.Mark - q.PreviousClose) * p.Quantity * pp.Multiplier
+	} else {
+		pp.DayChange = q.Change * p.Quantity * pp.Multiplier
+	}
+
+	return pp
+}
+
+// QuoteMark returns the mid of the bid and ask if both are set,
+// otherwise the last trade price.
+func QuoteMark(q *Quote) float64 {
+	if q.Bid > 0 && q.Ask > 0 && q.Ask >= q.Bid {
+		return (q.Bid + q.Ask) / 2
+	}
+	return q.Last
+}
+
+func acquiredOn(acquired, now time.Time) bool {
+	if acquired.IsZero() {
+		return false
+	}
+	y1, m1, d1 := acquired.Date()
+	y2, m2, d2 := now.In(acquired.Location()).Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
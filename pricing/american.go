@@ -0,0 +1,79 @@
+package pricing
+
+import (
+	"math"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+// BjerksundStensland is the Bjerksund-Stensland (1993) approximation
+// of the value of American options.
+type BjerksundStensland struct{}
+
+var _ Model = BjerksundStensland{}
+
+func (BjerksundStensland) Price(o Option, vol float64) float64 {
+	if o.Expiry <= 0 || vol <= 0 {
+		return o.Intrinsic()
+	}
+
+	var price float64
+	if o.IsCall() {
+		price = bsCall(o.Spot, o.Strike, o.Expiry, o.Rate, o.Rate-o.DividendYield, vol)
+	} else {
+		// Put-call transformation.
+		price = bsCall(o.Strike, o.Spot, o.Expiry, o.DividendYield, o.DividendYield-o.Rate, vol)
+	}
+
+	// The approximation is a lower bound; it may fall below
+	// the European value or intrinsic value at the extremes.
+	return math.Max(price, math.Max(BlackScholes{}.Price(o, vol), o.Intrinsic()))
+}
+
+func (m BjerksundStensland) Greeks(o Option, vol float64) tradier.Greeks {
+	return numericGreeks(m, o, vol)
+}
+
+// The value of an American call with cost of carry b.
+func bsCall(s, k, t, r, b, vol float64) float64 {
+	european := BlackScholes{}.Price(Option{
+		Type:          tradier.Call,
+		Spot:          s,
+		Strike:        k,
+		Expiry:        t,
+		Rate:          r,
+		DividendYield: r - b,
+	}, vol)
+	if b >= r {
+		// Never optimal to exercise early.
+		return european
+	}
+
+	v2 := vol * vol
+	beta := (0.5 - b/v2) + math.Sqrt(math.Pow(b/v2-0.5, 2)+2*r/v2)
+	bInf := beta / (beta - 1) * k
+	b0 := math.Max(k, r/(r-b)*k)
+	h := -(b*t + 2*vol*math.Sqrt(t)) * b0 / (bInf - b0)
+	trigger := b0 + (bInf-b0)*(1-math.Exp(h))
+	if s >= trigger {
+		return s - k
+	}
+
+	alpha := (trigger - k) * math.Pow(trigger, -beta)
+	return alpha*math.Pow(s, beta) -
+		alpha*bsPhi(s, t, beta, trigger, trigger, r, b, vol) +
+		bsPhi(s, t, 1, trigger, trigger, r, b, vol) -
+		bsPhi(s, t, 1, k, trigger, r, b, vol) -
+		k*bsPhi(s, t, 0, trigger, trigger, r, b, vol) +
+		k*bsPhi(s, t, 0, k, trigger, r, b, vol)
+}
+
+func bsPhi(s, t, gamma, h, trigger, r, b, vol float64) float64 {
+	v2 := vol * vol
+	volT := vol * math.Sqrt(t)
+	lambda := (-r + gamma*b + 0.5*gamma*(gamma-1)*v2) * t
+	d := -(math.Log(s/h) + (b+(gamma-0.5)*v2)*t) / volT
+	kappa := 2*b/v2 + (2*gamma - 1)
+	return math.Exp(lambda) * math.Pow(s, gamma) *
+		(normCDF(d) - math.Pow(trigger/s, kappa)*normCDF(d-2*math.Log(trigger/s)/volT))
+}
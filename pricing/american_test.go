@@ -0,0 +1,71 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+func TestBjerksundStenslandAtLeastEuropean(t *testing.T) {
+	for _, optionType := range []string{tradier.Call, tradier.Put} {
+		for _, strike := range []float64{60, 90, 100, 110, 150} {
+			for _, dividendYield := range []float64{0, 0.03, 0.12} {
+				for _, vol := range []float64{0.1, 0.4} {
+					o := Option{
+						Type:          optionType,
+						Spot:          100,
+						Strike:        strike,
+						Expiry:        1,
+						Rate:          0.05,
+						DividendYield: dividendYield,
+					}
+					american := BjerksundStensland{}.Price(o, vol)
+					european := BlackScholes{}.Price(o, vol)
+					if american < european-1e-12 || american < o.Intrinsic()-1e-12 {
+						t.Errorf("%+v at vol %v: American %v is below European %v or intrinsic %v",
+							o, vol, american, european, o.Intrinsic())
+					}
+				}
+			}
+		}
+	}
+
+	// Early exercise of a deep in the money put is worth something.
+	o := Option{Type: tradier.Put, Spot: 60, Strike: 100, Expiry: 1, Rate: 0.05}
+	american, european := BjerksundStensland{}.Price(o, 0.2), BlackScholes{}.Price(o, 0.2)
+	if american <= european {
+		t.Errorf("expected American put %v to be worth more than European %v", american, european)
+	}
+}
+
+func TestBjerksundStenslandCallWithoutDividends(t *testing.T) {
+	// Without dividends it is never optimal to exercise a call early.
+	o := Option{Type: tradier.Call, Spot: 100, Strike: 95, Expiry: 0.75, Rate: 0.05}
+	assertClose(t, "call", BjerksundStensland{}.Price(o, 0.3), BlackScholes{}.Price(o, 0.3), 1e-12)
+}
+
+func TestBjerksundStenslandReferenceValues(t *testing.T) {
+	// Hull, Options, Futures, and Other Derivatives: an American put with
+	// S = K = 50, r = 10%, vol = 40% and five months to expiration is worth
+	// 4.283 with a 500-step binomial tree. Bjerksund-Stensland (1993) is a
+	// lower bound that is accurate to within about 2%.
+	o := Option{Type: tradier.Put, Spot: 50, Strike: 50, Expiry: 5.0 / 12, Rate: 0.1}
+	price := BjerksundStensland{}.Price(o, 0.4)
+	assertClose(t, "Hull put", price, 4.283, 0.08)
+	if price > 4.283 {
+		t.Errorf("expected approximation %v to be below the binomial value", price)
+	}
+
+	// Haug, The Complete Guide to Option Pricing Formulas: American calls
+	// on futures (b = 0) with K = 100, r = 10%, vol = 15% and T = 0.1.
+	for _, tc := range []struct {
+		spot, expected float64
+	}{
+		{90, 0.0205},
+		{100, 1.8757},
+		{110, 10.0000},
+	} {
+		o := Option{Type: tradier.Call, Spot: tc.spot, Strike: 100, Expiry: 0.1, Rate: 0.1, DividendYield: 0.1}
+		assertClose(t, "Haug call", BjerksundStensland{}.Price(o, 0.15), tc.expected, 1e-3)
+	}
+}
@@ -0,0 +1,66 @@
+package pricing
+
+import (
+	"math"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+// BlackScholes is the Black-Scholes-Merton model of European options
+// on an underlying with a continuous dividend yield.
+type BlackScholes struct{}
+
+var _ Model = BlackScholes{}
+
+func (BlackScholes) Price(o Option, vol float64) float64 {
+	if o.Expiry <= 0 || vol <= 0 {
+		return o.Intrinsic()
+	}
+
+	d1, d2 := bsD(o, vol)
+	spot := o.Spot * math.Exp(-o.DividendYield*o.Expiry)
+	strike := o.Strike * math.Exp(-o.Rate*o.Expiry)
+	if o.IsCall() {
+		return spot*normCDF(d1) - strike*normCDF(d2)
+	}
+	return strike*normCDF(-d2) - spot*normCDF(-d1)
+}
+
+func (BlackScholes) Greeks(o Option, vol float64) tradier.Greeks {
+	var g tradier.Greeks
+	if o.Expiry <= 0 || vol <= 0 {
+		if o.Intrinsic() > 0 {
+			g.Delta = 1
+			if !o.IsCall() {
+				g.Delta = -1
+			}
+		}
+		return g
+	}
+
+	d1, d2 := bsD(o, vol)
+	sqrtT := math.Sqrt(o.Expiry)
+	qDisc := math.Exp(-o.DividendYield * o.Expiry)
+	rDisc := math.Exp(-o.Rate * o.Expiry)
+
+	g.Gamma = tradier.BlackScholesGamma(o.Spot, o.Strike, o.Expiry, o.Rate, o.DividendYield, vol)
+	g.Vega = o.Spot * qDisc * normPDF(d1) * sqrtT / 100
+	decay := -o.Spot * qDisc * normPDF(d1) * vol / (2 * sqrtT)
+	if o.IsCall() {
+		g.Delta = qDisc * normCDF(d1)
+		g.Theta = decay - o.Rate*o.Strike*rDisc*normCDF(d2) + o.DividendYield*o.Spot*qDisc*normCDF(d1)
+		g.Rho = o.Strike * o.Expiry * rDisc * normCDF(d2) / 100
+	} else {
+		g.Delta = -qDisc * normCDF(-d1)
+		g.Theta = decay + o.Rate*o.Strike*rDisc*normCDF(-d2) - o.DividendYield*o.Spot*qDisc*normCDF(-d1)
+		g.Rho = -o.Strike * o.Expiry * rDisc * normCDF(-d2) / 100
+	}
+	g.Theta /= daysPerYear
+	return g
+}
+
+func bsD(o Option, vol float64) (d1, d2 float64) {
+	volT := vol * math.Sqrt(o.Expiry)
+	d1 = (math.Log(o.Spot/o.Strike) + (o.Rate-o.DividendYield+0.5*vol*vol)*o.Expiry) / volT
+	return d1, d1 - volT
+}
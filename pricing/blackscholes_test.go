@@ -0,0 +1,76 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+func assertClose(t *testing.T, name string, got, expected, tol float64) {
+	t.Helper()
+	if math.IsNaN(got) || math.Abs(got-expected) > tol {
+		t.Errorf("%v: got %v, expected %v (+/- %v)", name, got, expected, tol)
+	}
+}
+
+func TestBlackScholesReferenceValues(t *testing.T) {
+	testCases := []struct {
+		name     string
+		o        Option
+		vol      float64
+		expected float64
+	}{
+		// Hull, Options, Futures, and Other Derivatives.
+		{"Hull call", Option{Type: tradier.Call, Spot: 42, Strike: 40, Expiry: 0.5, Rate: 0.1}, 0.2, 4.7594},
+		{"Hull put", Option{Type: tradier.Put, Spot: 42, Strike: 40, Expiry: 0.5, Rate: 0.1}, 0.2, 0.8086},
+		// Haug, The Complete Guide to Option Pricing Formulas, generalized
+		// Black-Scholes-Merton put with cost of carry b = r - q = 0.05.
+		{"Haug put with dividends", Option{Type: tradier.Put, Spot: 100, Strike: 95, Expiry: 0.5, Rate: 0.1, DividendYield: 0.05}, 0.2, 2.4648},
+	}
+
+	for _, tc := range testCases {
+		assertClose(t, tc.name, BlackScholes{}.Price(tc.o, tc.vol), tc.expected, 1e-4)
+	}
+}
+
+func TestBlackScholesPutCallParity(t *testing.T) {
+	for _, strike := range []float64{50, 90, 100, 110, 200} {
+		for _, expiry := range []float64{0.01, 0.25, 2} {
+			for _, vol := range []float64{0.05, 0.3, 1.5} {
+				call := Option{
+					Type:          tradier.Call,
+					Spot:          100,
+					Strike:        strike,
+					Expiry:        expiry,
+					Rate:          0.05,
+					DividendYield: 0.02,
+				}
+				put := call
+				put.Type = tradier.Put
+
+				parity := call.Spot*math.Exp(-call.DividendYield*expiry) - strike*math.Exp(-call.Rate*expiry)
+				diff := BlackScholes{}.Price(call, vol) - BlackScholes{}.Price(put, vol)
+				assertClose(t, "call - put", diff, parity, 1e-9)
+
+				// Delta parity: call delta - put delta = exp(-qT).
+				cg, pg := BlackScholes{}.Greeks(call, vol), BlackScholes{}.Greeks(put, vol)
+				assertClose(t, "call delta - put delta", cg.Delta-pg.Delta, math.Exp(-call.DividendYield*expiry), 1e-9)
+				assertClose(t, "call gamma - put gamma", cg.Gamma-pg.Gamma, 0, 1e-12)
+			}
+		}
+	}
+}
+
+func TestBlackScholesGreeksMatchFiniteDifferences(t *testing.T) {
+	for _, optionType := range []string{tradier.Call, tradier.Put} {
+		o := Option{Type: optionType, Spot: 100, Strike: 105, Expiry: 0.5, Rate: 0.03, DividendYield: 0.01}
+		analytic := BlackScholes{}.Greeks(o, 0.25)
+		numeric := numericGreeks(BlackScholes{}, o, 0.25)
+		assertClose(t, optionType+" delta", analytic.Delta, numeric.Delta, 1e-4)
+		assertClose(t, optionType+" gamma", analytic.Gamma, numeric.Gamma, 1e-4)
+		assertClose(t, optionType+" vega", analytic.Vega, numeric.Vega, 1e-4)
+		assertClose(t, optionType+" theta", analytic.Theta, numeric.Theta, 1e-3)
+		assertClose(t, optionType+" rho", analytic.Rho, numeric.Rho, 1e-4)
+	}
+}
@@ -0,0 +1,9 @@
+// Package pricing implements option pricing models and an implied
+// volatility solver, so that greeks can be computed locally for any
+// option, price or point in time rather than only for listed contracts
+// at quote time.
+//
+// Greeks follow the same conventions as Tradier: theta is per calendar
+// day, and vega and rho are per one percentage point change in
+// volatility and interest rates, respectively.
+package pricing
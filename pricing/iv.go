@@ -0,0 +1,58 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	ErrPriceOutOfRange = errors.New("price is outside the range of the model")
+	ErrNoConvergence   = errors.New("implied volatility did not converge")
+)
+
+const (
+	minVol = 1e-4
+	maxVol = 5.0
+)
+
+// ImpliedVol returns the volatility at which the model prices the option
+// at the given price. It uses Newton's method, falling back to bisection
+// whenever a Newton step would leave the bracketing interval, so that it
+// converges even for deep in or out of the money options.
+func ImpliedVol(model Model, o Option, price float64) (float64, error) {
+	if o.Expiry <= 0 || math.IsNaN(price) {
+		return 0, ErrPriceOutOfRange
+	}
+
+	lo, hi := minVol, maxVol
+	tol := math.Max(1e-12, 1e-9*price)
+	if price < model.Price(o, lo)-tol || price > model.Price(o, hi)+tol {
+		return 0, ErrPriceOutOfRange
+	}
+
+	vol := 0.3
+	for i := 0; i < 100; i++ {
+		diff := model.Price(o, vol) - price
+		if math.Abs(diff) < tol {
+			return vol, nil
+		}
+		if diff > 0 {
+			hi = vol
+		} else {
+			lo = vol
+		}
+		if hi-lo < 1e-10 {
+			return vol, nil
+		}
+
+		next := (lo + hi) / 2
+		if vega := model.Greeks(o, vol).Vega * 100; vega > 1e-10 {
+			if step := vol - diff/vega; step > lo && step < hi {
+				next = step
+			}
+		}
+		vol = next
+	}
+
+	return 0, ErrNoConvergence
+}
@@ -0,0 +1,75 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+// A model with no vega, so that ImpliedVol can only bisect.
+type noVegaModel struct {
+	Model
+}
+
+func (noVegaModel) Greeks(o Option, vol float64) tradier.Greeks {
+	return tradier.Greeks{}
+}
+
+func TestImpliedVolRoundTrip(t *testing.T) {
+	models := map[string]Model{
+		"black-scholes":       BlackScholes{},
+		"bjerksund-stensland": BjerksundStensland{},
+		"bisection":           noVegaModel{BlackScholes{}},
+	}
+
+	for name, model := range models {
+		for _, optionType := range []string{tradier.Call, tradier.Put} {
+			for _, strike := range []float64{50, 80, 100, 120, 200} {
+				for _, vol := range []float64{0.05, 0.2, 0.8, 2.5} {
+					o := Option{
+						Type:          optionType,
+						Spot:          100,
+						Strike:        strike,
+						Expiry:        0.5,
+						Rate:          0.03,
+						DividendYield: 0.01,
+					}
+					// Prices that barely depend on volatility do not determine it.
+					if vega := (BlackScholes{}).Greeks(o, vol).Vega; vega < 1e-4 {
+						continue
+					}
+					price := model.Price(o, vol)
+
+					iv, err := ImpliedVol(model, o, price)
+					if err != nil {
+						t.Errorf("%v %+v at vol %v: %v", name, o, vol, err)
+						continue
+					}
+					assertClose(t, name+" price at implied vol", model.Price(o, iv), price, 1e-6)
+					assertClose(t, name+" implied vol", iv, vol, 1e-4)
+				}
+			}
+		}
+	}
+}
+
+func TestImpliedVolNoSolution(t *testing.T) {
+	o := Option{Type: tradier.Call, Spot: 100, Strike: 90, Expiry: 0.5, Rate: 0.03}
+	testCases := []struct {
+		name  string
+		o     Option
+		price float64
+	}{
+		{"below intrinsic", o, 5},
+		{"above spot", o, 101},
+		{"NaN price", o, math.NaN()},
+		{"expired", Option{Type: tradier.Call, Spot: 100, Strike: 90}, 10},
+	}
+
+	for _, tc := range testCases {
+		if _, err := ImpliedVol(BlackScholes{}, tc.o, tc.price); err != ErrPriceOutOfRange {
+			t.Errorf("%v: expected %v, got %v", tc.name, ErrPriceOutOfRange, err)
+		}
+	}
+}
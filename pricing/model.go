@@ -0,0 +1,76 @@
+package pricing
+
+import (
+	"math"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+// Option holds the inputs to a pricing model, other than volatility.
+type Option struct {
+	// tradier.Call or tradier.Put.
+	Type   string
+	Spot   float64
+	Strike float64
+	// Time to expiration, in years.
+	Expiry float64
+	// Continuously compounded risk-free rate.
+	Rate float64
+	// Continuous dividend yield.
+	DividendYield float64
+}
+
+func (o Option) IsCall() bool {
+	return o.Type == tradier.Call
+}
+
+// Intrinsic returns the value of the option if exercised now.
+func (o Option) Intrinsic() float64 {
+	if o.IsCall() {
+		return math.Max(o.Spot-o.Strike, 0)
+	}
+	return math.Max(o.Strike-o.Spot, 0)
+}
+
+// Model is an option pricing model.
+type Model interface {
+	Price(o Option, vol float64) float64
+	Greeks(o Option, vol float64) tradier.Greeks
+}
+
+const daysPerYear = tradier.DaysPerYear
+
+// Greeks computed by finite differences of the model price.
+func numericGreeks(model Model, o Option, vol float64) tradier.Greeks {
+	price := func(spot, vol, expiry, rate float64) float64 {
+		shifted := o
+		shifted.Spot, shifted.Expiry, shifted.Rate = spot, expiry, rate
+		return model.Price(shifted, vol)
+	}
+
+	p := model.Price(o, vol)
+	dS := 0.001 * o.Spot
+	up := price(o.Spot+dS, vol, o.Expiry, o.Rate)
+	down := price(o.Spot-dS, vol, o.Expiry, o.Rate)
+
+	const dVol, dRate = 0.001, 0.0001
+	dT := math.Min(1.0/daysPerYear, o.Expiry)
+
+	var g tradier.Greeks
+	g.Delta = (up - down) / (2 * dS)
+	g.Gamma = (up - 2*p + down) / (dS * dS)
+	g.Vega = (price(o.Spot, vol+dVol, o.Expiry, o.Rate) - price(o.Spot, vol-dVol, o.Expiry, o.Rate)) / (2 * dVol) / 100
+	g.Rho = (price(o.Spot, vol, o.Expiry, o.Rate+dRate) - price(o.Spot, vol, o.Expiry, o.Rate-dRate)) / (2 * dRate) / 100
+	if dT > 0 {
+		g.Theta = (price(o.Spot, vol, o.Expiry-dT, o.Rate) - p) / dT / daysPerYear
+	}
+	return g
+}
+
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func normPDF(x float64) float64 {
+	return math.Exp(-0.5*x*x) / math.Sqrt(2*math.Pi)
+}
@@ -0,0 +1,80 @@
+package pricing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+// OptionFromQuote returns the pricing inputs for an option quote,
+// given the underlying price, risk-free rate and dividend yield.
+func OptionFromQuote(q *tradier.Quote, spot, rate, dividendYield float64, now time.Time) (Option, error) {
+	if q.OptionType != tradier.Call && q.OptionType != tradier.Put {
+		return Option{}, fmt.Errorf("%v is not an option", q.Symbol)
+	}
+	if q.Strike <= 0 || q.ExpirationDate.IsZero() {
+		return Option{}, fmt.Errorf("%v has no strike or expiration", q.Symbol)
+	}
+
+	return Option{
+		Type:          q.OptionType,
+		Spot:          spot,
+		Strike:        q.Strike,
+		Expiry:        tradier.YearsToExpiration(q.ExpirationDate.Time, now),
+		Rate:          rate,
+		DividendYield: dividendYield,
+	}, nil
+}
+
+// QuoteGreeks computes the implied volatility of the bid, ask and mid
+// prices of an option quote, and its greeks at the mid implied volatility.
+// Bid and ask IVs are left zero if they can't be solved for.
+func QuoteGreeks(model Model, q *tradier.Quote, spot, rate, dividendYield float64, now time.Time) (tradier.Greeks, error) {
+	o, err := OptionFromQuote(q, spot, rate, dividendYield, now)
+	if err != nil {
+		return tradier.Greeks{}, err
+	}
+
+	mid := tradier.QuoteMark(q)
+	midIV, err := ImpliedVol(model, o, mid)
+	if err != nil {
+		return tradier.Greeks{}, fmt.Errorf("error computing implied volatility of %v at %v: %v", q.Symbol, mid, err)
+	}
+
+	greeks := model.Greeks(o, midIV)
+	greeks.MidIV = midIV
+	if q.Bid > 0 {
+		greeks.BidIV, _ = ImpliedVol(model, o, q.Bid)
+	}
+	if q.Ask > 0 {
+		greeks.AskIV, _ = ImpliedVol(model, o, q.Ask)
+	}
+	return greeks, nil
+}
+
+// DividendYield estimates the dividend yield of the underlying from
+// the dividends with ex-dates in the year before now, as returned by
+// GetDividends (see tradier.DividendsFromResponse).
+func DividendYield(dividends []tradier.CashDividend, spot float64, now time.Time) float64 {
+	if spot <= 0 {
+		return 0
+	}
+
+	yearAgo := now.AddDate(-1, 0, 0)
+	var total float64
+	seen := make(map[string]bool)
+	for _, d := range dividends {
+		if d.ExDate == nil || d.CashAmount == nil || seen[*d.ExDate] {
+			continue
+		}
+		exDate, err := time.Parse("2006-01-02", *d.ExDate)
+		if err != nil || exDate.Before(yearAgo) || exDate.After(now) {
+			continue
+		}
+		seen[*d.ExDate] = true
+		total += *d.CashAmount
+	}
+
+	return total / spot
+}
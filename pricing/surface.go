@@ -0,0 +1,344 @@
+package pricing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+// IVSource selects where the implied volatility of each option comes from.
+type IVSource int
+
+const (
+	// Greeks.MidIV, as reported by Tradier.
+	IVFromMid IVSource = iota
+	// Greeks.SmvVol, Tradier's smoothed volatility.
+	IVFromSmv
+	// Solved locally from the mid price with SurfaceParams.Model.
+	IVFromModel
+)
+
+type SurfaceParams struct {
+	Source IVSource
+	// Model used to solve for IV if Source is IVFromModel.
+	// Defaults to BjerksundStensland.
+	Model         Model
+	Rate          float64
+	DividendYield float64
+	// Time at which the surface is built. Defaults to time.Now().
+	Now time.Time
+}
+
+// SmilePoint is the implied volatility of the out-of-the-money option at a strike.
+type SmilePoint struct {
+	Strike float64
+	// Log-moneyness ln(Strike / Forward).
+	LogMoneyness float64
+	IV           float64
+}
+
+// Smile is the implied volatility across strikes of a single expiration.
+type Smile struct {
+	Expiration time.Time
+	// Time to expiration, in years.
+	T       float64
+	Forward float64
+	Points  []SmilePoint
+	// The SVI fit, if there were enough points for one.
+	SVI    SVI
+	Fitted bool
+	// Root mean squared error of the fit, in total variance.
+	FitError float64
+}
+
+// TotalVariance returns IV^2 * T at the given log-moneyness, from the SVI
+// fit if there is one and otherwise by linear interpolation between points.
+func (s *Smile) TotalVariance(k float64) float64 {
+	if s.Fitted {
+		return math.Max(s.SVI.TotalVariance(k), 0)
+	}
+
+	pts := s.Points
+	if len(pts) == 0 {
+		return math.NaN()
+	}
+	i := sort.Search(len(pts), func(i int) bool { return pts[i].LogMoneyness >= k })
+	if i == 0 {
+		return pts[0].IV * pts[0].IV * s.T
+	} else if i == len(pts) {
+		return pts[i-1].IV * pts[i-1].IV * s.T
+	}
+
+	lo, hi := pts[i-1], pts[i]
+	f := (k - lo.LogMoneyness) / (hi.LogMoneyness - lo.LogMoneyness)
+	return ((1-f)*lo.IV*lo.IV + f*hi.IV*hi.IV) * s.T
+}
+
+// Vol returns the implied volatility at the given strike.
+func (s *Smile) Vol(strike float64) float64 {
+	return math.Sqrt(s.TotalVariance(math.Log(strike/s.Forward)) / s.T)
+}
+
+// Return the log-moneyness at which the forward delta of a call (or put,
+// if delta < 0) equals delta.
+func (s *Smile) deltaLogMoneyness(delta float64) float64 {
+	callDelta := delta
+	if delta < 0 {
+		callDelta = 1 + delta
+	}
+
+	// Call delta N(d1) decreases with k.
+	lo, hi := -3.0, 3.0
+	for i := 0; i < 100; i++ {
+		k := (lo + hi) / 2
+		w := s.TotalVariance(k)
+		// With no variance, delta is 1 in the money and 0 out of it.
+		d1 := math.Copysign(math.Inf(1), -k)
+		if w > 0 {
+			d1 = (-k + w/2) / math.Sqrt(w)
+		}
+		if normCDF(d1) > callDelta {
+			lo = k
+		} else {
+			hi = k
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// IVSurface is the implied volatility of an underlying across
+// strikes and expirations.
+type IVSurface struct {
+	Underlying    string
+	Spot          float64
+	Rate          float64
+	DividendYield float64
+	// Sorted by expiration.
+	Smiles []*Smile
+}
+
+// NewIVSurface builds a surface from the out-of-the-money options in the
+// chain: puts below the forward price and calls above it. The smile of
+// each expiration is fit with SVI.
+func NewIVSurface(chain *tradier.OptionChain, params SurfaceParams) (*IVSurface, error) {
+	if chain.Spot <= 0 {
+		return nil, fmt.Errorf("no spot price for %v", chain.Underlying)
+	}
+	if params.Model == nil {
+		params.Model = BjerksundStensland{}
+	}
+	if params.Now.IsZero() {
+		params.Now = time.Now()
+	}
+
+	surface := &IVSurface{
+		Underlying:    chain.Underlying,
+		Spot:          chain.Spot,
+		Rate:          params.Rate,
+		DividendYield: params.DividendYield,
+	}
+	for _, exp := range chain.Expirations() {
+		t := chain.YearsToExpiration(exp, params.Now)
+		if t <= 0 {
+			continue
+		}
+
+		smile := &Smile{
+			Expiration: exp,
+			T:          t,
+			Forward:    surface.Forward(t),
+		}
+		for _, pair := range chain.Strikes(exp) {
+			q := pair.Call
+			if pair.Strike < smile.Forward {
+				q = pair.Put
+			}
+			if q == nil {
+				continue
+			}
+
+			iv := surface.impliedVol(q, params)
+			if iv > 0 && !math.IsNaN(iv) {
+				smile.Points = append(smile.Points, SmilePoint{
+					Strike:       pair.Strike,
+					LogMoneyness: math.Log(pair.Strike / smile.Forward),
+					IV:           iv,
+				})
+			}
+		}
+		if len(smile.Points) == 0 {
+			continue
+		}
+
+		k := make([]float64, len(smile.Points))
+		w := make([]float64, len(smile.Points))
+		for i, p := range smile.Points {
+			k[i], w[i] = p.LogMoneyness, p.IV*p.IV*t
+		}
+		smile.SVI, smile.FitError, smile.Fitted = FitSVI(k, w)
+		surface.Smiles = append(surface.Smiles, smile)
+	}
+
+	if len(surface.Smiles) == 0 {
+		return nil, fmt.Errorf("no implied volatilities for %v", chain.Underlying)
+	}
+	return surface, nil
+}
+
+func (s *IVSurface) impliedVol(q *tradier.Quote, params SurfaceParams) float64 {
+	switch params.Source {
+	case IVFromMid:
+		return q.Greeks.MidIV
+	case IVFromSmv:
+		return q.Greeks.SmvVol
+	}
+
+	if q.Bid <= 0 || q.Ask <= 0 {
+		return 0
+	}
+	o, err := OptionFromQuote(q, s.Spot, s.Rate, s.DividendYield, params.Now)
+	if err != nil {
+		return 0
+	}
+	iv, err := ImpliedVol(params.Model, o, tradier.QuoteMark(q))
+	if err != nil {
+		return 0
+	}
+	return iv
+}
+
+// Forward returns the forward price of the underlying at time t (in years).
+func (s *IVSurface) Forward(t float64) float64 {
+	return s.Spot * math.Exp((s.Rate-s.DividendYield)*t)
+}
+
+// Vol returns the implied volatility at the given strike and time to
+// expiration (in years). Between expirations total variance is
+// interpolated linearly in time at constant log-moneyness; beyond the
+// first and last expirations volatility is held constant.
+func (s *IVSurface) Vol(strike, t float64) float64 {
+	k := math.Log(strike / s.Forward(t))
+	first, last := s.Smiles[0], s.Smiles[len(s.Smiles)-1]
+	if t <= first.T {
+		return math.Sqrt(first.TotalVariance(k) / first.T)
+	} else if t >= last.T {
+		return math.Sqrt(last.TotalVariance(k) / last.T)
+	}
+
+	i := sort.Search(len(s.Smiles), func(i int) bool { return s.Smiles[i].T >= t })
+	lo, hi := s.Smiles[i-1], s.Smiles[i]
+	f := (t - lo.T) / (hi.T - lo.T)
+	w := (1-f)*lo.TotalVariance(k) + f*hi.TotalVariance(k)
+	return math.Sqrt(w / t)
+}
+
+// TermPoint is the at-the-money (forward) volatility of an expiration.
+type TermPoint struct {
+	Expiration time.Time
+	T          float64
+	ATMVol     float64
+}
+
+// ATMTermStructure returns the at-the-money volatility of each expiration.
+func (s *IVSurface) ATMTermStructure() []TermPoint {
+	result := make([]TermPoint, len(s.Smiles))
+	for i, smile := range s.Smiles {
+		result[i] = TermPoint{
+			Expiration: smile.Expiration,
+			T:          smile.T,
+			ATMVol:     math.Sqrt(smile.TotalVariance(0) / smile.T),
+		}
+	}
+	return result
+}
+
+// DeltaSkew summarizes the skew of an expiration at a given delta.
+type DeltaSkew struct {
+	Expiration time.Time
+	ATMVol     float64
+	PutVol     float64
+	CallVol    float64
+	// CallVol - PutVol.
+	RiskReversal float64
+	// (CallVol + PutVol) / 2 - ATMVol.
+	Butterfly float64
+}
+
+// Skew returns the volatility of the put and call with the given (absolute)
+// forward delta, e.g. 0.25, along with the risk reversal and butterfly.
+func (s *IVSurface) Skew(delta float64) []DeltaSkew {
+	delta = math.Abs(delta)
+	result := make([]DeltaSkew, len(s.Smiles))
+	for i, smile := range s.Smiles {
+		vol := func(k float64) float64 {
+			return math.Sqrt(smile.TotalVariance(k) / smile.T)
+		}
+
+		ds := DeltaSkew{
+			Expiration: smile.Expiration,
+			ATMVol:     vol(0),
+			PutVol:     vol(smile.deltaLogMoneyness(-delta)),
+			CallVol:    vol(smile.deltaLogMoneyness(delta)),
+		}
+		ds.RiskReversal = ds.CallVol - ds.PutVol
+		ds.Butterfly = (ds.CallVol+ds.PutVol)/2 - ds.ATMVol
+		result[i] = ds
+	}
+	return result
+}
+
+// SurfaceGrid is the surface evaluated on a grid, e.g. for plotting.
+type SurfaceGrid struct {
+	// Strike / forward price.
+	Moneyness   []float64
+	Expirations []time.Time
+	// Vols[i][j] is the volatility of Expirations[i] at Moneyness[j].
+	Vols [][]float64
+}
+
+// Grid evaluates the surface at each expiration for the given
+// moneyness (strike / forward) values.
+func (s *IVSurface) Grid(moneyness []float64) SurfaceGrid {
+	grid := SurfaceGrid{Moneyness: moneyness}
+	for _, smile := range s.Smiles {
+		row := make([]float64, len(moneyness))
+		for j, m := range moneyness {
+			row[j] = math.Sqrt(smile.TotalVariance(math.Log(m)) / smile.T)
+		}
+		grid.Expirations = append(grid.Expirations, smile.Expiration)
+		grid.Vols = append(grid.Vols, row)
+	}
+	return grid
+}
+
+// WriteCSV writes the grid with one row per expiration
+// and one column per moneyness.
+func (g SurfaceGrid) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"expiration"}
+	for _, m := range g.Moneyness {
+		header = append(header, strconv.FormatFloat(m, 'f', -1, 64))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for i, exp := range g.Expirations {
+		row := []string{exp.Format("2006-01-02")}
+		for _, vol := range g.Vols[i] {
+			row = append(row, strconv.FormatFloat(vol, 'f', 6, 64))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
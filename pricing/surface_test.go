@@ -0,0 +1,102 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-tradier"
+)
+
+// A smile with the same volatility at every strike.
+func flatSmile(t, vol float64) *Smile {
+	return &Smile{T: t, Forward: 100, Points: []SmilePoint{{Strike: 100, IV: vol}}}
+}
+
+func TestSmileInterpolation(t *testing.T) {
+	smile := &Smile{T: 0.5, Forward: 100, Points: []SmilePoint{
+		{Strike: 90, LogMoneyness: -0.1, IV: 0.3},
+		{Strike: 110, LogMoneyness: 0.1, IV: 0.2},
+	}}
+	// Total variance is linear in log-moneyness between points.
+	assertClose(t, "midpoint", smile.TotalVariance(0), (0.09+0.04)/2*0.5, 1e-12)
+	// And flat beyond them.
+	assertClose(t, "left wing", smile.TotalVariance(-1), 0.09*0.5, 1e-12)
+	assertClose(t, "right wing", smile.TotalVariance(1), 0.04*0.5, 1e-12)
+}
+
+func TestSurfaceVolInterpolatesTotalVariance(t *testing.T) {
+	s := &IVSurface{Spot: 100, Smiles: []*Smile{flatSmile(0.25, 0.2), flatSmile(1, 0.3)}}
+
+	assertClose(t, "first expiration", s.Vol(100, 0.25), 0.2, 1e-12)
+	assertClose(t, "last expiration", s.Vol(100, 1), 0.3, 1e-12)
+	assertClose(t, "before first expiration", s.Vol(100, 0.1), 0.2, 1e-12)
+	assertClose(t, "after last expiration", s.Vol(100, 2), 0.3, 1e-12)
+
+	// A third of the way from 0.25 to 1: w = 0.01 + (0.09 - 0.01) / 3.
+	w := 0.01 + 0.08/3
+	assertClose(t, "between expirations", s.Vol(100, 0.5), math.Sqrt(w/0.5), 1e-12)
+}
+
+func TestSurfaceSkew(t *testing.T) {
+	flat := &IVSurface{Spot: 100, Smiles: []*Smile{flatSmile(0.5, 0.2)}}
+	skew := flat.Skew(0.25)[0]
+	assertClose(t, "flat ATM vol", skew.ATMVol, 0.2, 1e-12)
+	assertClose(t, "flat risk reversal", skew.RiskReversal, 0, 1e-9)
+	assertClose(t, "flat butterfly", skew.Butterfly, 0, 1e-9)
+
+	// The forward delta of a call is 0.5 where d1 = 0, i.e. k = w / 2.
+	w := 0.2 * 0.2 * 0.5
+	assertClose(t, "50 delta", flat.Smiles[0].deltaLogMoneyness(0.5), w/2, 1e-9)
+
+	// Negative Rho makes puts more expensive than calls.
+	skewed := &IVSurface{Spot: 100, Smiles: []*Smile{{T: 0.5, Forward: 100, SVI: testSVI, Fitted: true}}}
+	skew = skewed.Skew(0.25)[0]
+	if skew.RiskReversal >= 0 {
+		t.Errorf("expected negative risk reversal, got %v", skew.RiskReversal)
+	}
+	if skew.PutVol <= skew.ATMVol || skew.Butterfly <= 0 {
+		t.Errorf("expected put vol %v above ATM vol %v and positive butterfly %v",
+			skew.PutVol, skew.ATMVol, skew.Butterfly)
+	}
+}
+
+func TestDeltaLogMoneynessWithoutVariance(t *testing.T) {
+	// Delta is 1 in the money and 0 out of it, so every delta is at the money.
+	smile := flatSmile(0.5, 0)
+	for _, delta := range []float64{0.25, -0.25, 0.5} {
+		assertClose(t, "log-moneyness", smile.deltaLogMoneyness(delta), 0, 1e-9)
+	}
+}
+
+func TestNewIVSurfaceFitsSmile(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, tradier.MarketLocation())
+	expiration := time.Date(2024, 7, 19, 0, 0, 0, 0, time.UTC)
+	expiry := tradier.YearsToExpiration(expiration, now)
+
+	var options []*tradier.Quote
+	for strike := 70.0; strike <= 130; strike += 5 {
+		iv := math.Sqrt(testSVI.TotalVariance(math.Log(strike/100)) / expiry)
+		for _, optionType := range []string{tradier.Call, tradier.Put} {
+			options = append(options, &tradier.Quote{
+				OptionType:     optionType,
+				Strike:         strike,
+				ExpirationDate: tradier.DateTime{Time: expiration},
+				Greeks:         tradier.Greeks{MidIV: iv},
+			})
+		}
+	}
+	chain := tradier.NewOptionChain("XYZ", 100, options)
+
+	surface, err := NewIVSurface(chain, SurfaceParams{Source: IVFromMid, Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(surface.Smiles) != 1 || !surface.Smiles[0].Fitted {
+		t.Fatalf("expected one fitted smile, got %+v", surface.Smiles)
+	}
+	for _, strike := range []float64{75, 100, 125} {
+		expected := math.Sqrt(testSVI.TotalVariance(math.Log(strike/100)) / expiry)
+		assertClose(t, "vol", surface.Vol(strike, expiry), expected, 1e-4)
+	}
+}
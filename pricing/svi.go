@@ -0,0 +1,220 @@
+package pricing
+
+import (
+	"math"
+	"sort"
+)
+
+// SVI is Gatheral's raw stochastic volatility inspired parameterization
+// of a volatility smile. Total implied variance (IV^2 * T) at
+// log-moneyness k = ln(K / F) is
+//
+//	w(k) = A + B * (Rho * (k - M) + sqrt((k - M)^2 + Sigma^2))
+type SVI struct {
+	A, B, Rho, M, Sigma float64
+}
+
+// TotalVariance returns w(k).
+func (s SVI) TotalVariance(k float64) float64 {
+	x := k - s.M
+	return s.A + s.B*(s.Rho*x+math.Sqrt(x*x+s.Sigma*s.Sigma))
+}
+
+// Whether the parameters give non-negative variance everywhere.
+func (s SVI) valid() bool {
+	return s.B >= 0 && math.Abs(s.Rho) < 1 && s.Sigma > 0 &&
+		s.A+s.B*s.Sigma*math.Sqrt(1-s.Rho*s.Rho) >= 0
+}
+
+// FitSVI fits SVI parameters to total variances w observed at
+// log-moneyness k, minimizing squared error. It uses the quasi-explicit
+// method of Zeliade: for fixed M and Sigma the remaining parameters are
+// found by linear least squares, leaving a two dimensional search.
+// Returns false if there are fewer than 5 points or no valid fit.
+func FitSVI(k, w []float64) (SVI, float64, bool) {
+	if len(k) < 5 || len(k) != len(w) {
+		return SVI{}, 0, false
+	}
+
+	objective := func(x []float64) (SVI, float64) {
+		m, sigma := x[0], math.Abs(x[1])
+		if sigma < 1e-4 {
+			sigma = 1e-4
+		}
+		svi, ok := fitSVILinear(k, w, m, sigma)
+		if !ok || !svi.valid() {
+			return svi, math.Inf(1)
+		}
+		return svi, sviError(svi, k, w)
+	}
+
+	kMin, kMax := minMax(k)
+	best := SVI{}
+	bestErr := math.Inf(1)
+	// Several starting points guard against local minima.
+	for _, m0 := range []float64{kMin / 2, 0, kMax / 2} {
+		for _, sigma0 := range []float64{0.05, 0.2, 0.5} {
+			x := nelderMead(func(x []float64) float64 {
+				_, err := objective(x)
+				return err
+			}, []float64{m0, sigma0}, 200)
+			if svi, err := objective(x); err < bestErr {
+				best, bestErr = svi, err
+			}
+		}
+	}
+
+	if math.IsInf(bestErr, 1) {
+		return SVI{}, 0, false
+	}
+	return best, math.Sqrt(bestErr / float64(len(k))), true
+}
+
+// Least squares fit of w = a + d*y + c*sqrt(y^2 + 1), y = (k - m) / sigma.
+func fitSVILinear(k, w []float64, m, sigma float64) (SVI, bool) {
+	// Normal equations for the basis (1, y, z).
+	var ata [3][3]float64
+	var atb [3]float64
+	for i := range k {
+		y := (k[i] - m) / sigma
+		basis := [3]float64{1, y, math.Sqrt(y*y + 1)}
+		for r := 0; r < 3; r++ {
+			for c := 0; c < 3; c++ {
+				ata[r][c] += basis[r] * basis[c]
+			}
+			atb[r] += basis[r] * w[i]
+		}
+	}
+
+	x, ok := solve3(ata, atb)
+	if !ok || x[2] <= 0 {
+		return SVI{}, false
+	}
+
+	a, d, c := x[0], x[1], x[2]
+	return SVI{
+		A:     a,
+		B:     c / sigma,
+		Rho:   d / c,
+		M:     m,
+		Sigma: sigma,
+	}, true
+}
+
+func sviError(s SVI, k, w []float64) float64 {
+	var sse float64
+	for i := range k {
+		e := s.TotalVariance(k[i]) - w[i]
+		sse += e * e
+	}
+	return sse
+}
+
+// Solve a 3x3 linear system by Cramer's rule.
+func solve3(a [3][3]float64, b [3]float64) ([3]float64, bool) {
+	det := func(m [3][3]float64) float64 {
+		return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+			m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+			m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	}
+
+	d := det(a)
+	if math.Abs(d) < 1e-14 {
+		return [3]float64{}, false
+	}
+
+	var x [3]float64
+	for i := 0; i < 3; i++ {
+		ai := a
+		for r := 0; r < 3; r++ {
+			ai[r][i] = b[r]
+		}
+		x[i] = det(ai) / d
+	}
+	return x, true
+}
+
+// Minimize f using the Nelder-Mead simplex method.
+func nelderMead(f func([]float64) float64, x0 []float64, maxIter int) []float64 {
+	n := len(x0)
+	simplex := make([][]float64, n+1)
+	values := make([]float64, n+1)
+	for i := range simplex {
+		simplex[i] = append([]float64{}, x0...)
+		if i > 0 {
+			simplex[i][i-1] += 0.1
+		}
+		values[i] = f(simplex[i])
+	}
+
+	point := func(centroid, towards []float64, t float64) []float64 {
+		p := make([]float64, n)
+		for j := range p {
+			p[j] = centroid[j] + t*(towards[j]-centroid[j])
+		}
+		return p
+	}
+
+	for iter := 0; iter < maxIter; iter++ {
+		sort.Sort(simplexSorter{simplex, values})
+		if math.Abs(values[n]-values[0]) < 1e-14 {
+			break
+		}
+
+		centroid := make([]float64, n)
+		for _, p := range simplex[:n] {
+			for j := range centroid {
+				centroid[j] += p[j] / float64(n)
+			}
+		}
+
+		reflected := point(centroid, simplex[n], -1)
+		fr := f(reflected)
+		switch {
+		case fr < values[0]:
+			expanded := point(centroid, simplex[n], -2)
+			if fe := f(expanded); fe < fr {
+				simplex[n], values[n] = expanded, fe
+			} else {
+				simplex[n], values[n] = reflected, fr
+			}
+		case fr < values[n-1]:
+			simplex[n], values[n] = reflected, fr
+		default:
+			contracted := point(centroid, simplex[n], 0.5)
+			if fc := f(contracted); fc < values[n] {
+				simplex[n], values[n] = contracted, fc
+			} else {
+				// Shrink towards the best point.
+				for i := 1; i <= n; i++ {
+					simplex[i] = point(simplex[0], simplex[i], 0.5)
+					values[i] = f(simplex[i])
+				}
+			}
+		}
+	}
+
+	sort.Sort(simplexSorter{simplex, values})
+	return simplex[0]
+}
+
+type simplexSorter struct {
+	points [][]float64
+	values []float64
+}
+
+func (s simplexSorter) Len() int           { return len(s.values) }
+func (s simplexSorter) Less(i, j int) bool { return s.values[i] < s.values[j] }
+func (s simplexSorter) Swap(i, j int) {
+	s.points[i], s.points[j] = s.points[j], s.points[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
+
+func minMax(xs []float64) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, x := range xs {
+		lo = math.Min(lo, x)
+		hi = math.Max(hi, x)
+	}
+	return lo, hi
+}
@@ -0,0 +1,44 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+)
+
+var testSVI = SVI{A: 0.04, B: 0.1, Rho: -0.4, M: 0.05, Sigma: 0.2}
+
+func TestFitSVIRecoversParameters(t *testing.T) {
+	var k, w []float64
+	for x := -0.5; x <= 0.5+1e-9; x += 0.05 {
+		k = append(k, x)
+		w = append(w, testSVI.TotalVariance(x))
+	}
+
+	fit, rmse, ok := FitSVI(k, w)
+	if !ok {
+		t.Fatal("expected a fit")
+	}
+	assertClose(t, "A", fit.A, testSVI.A, 1e-4)
+	assertClose(t, "B", fit.B, testSVI.B, 1e-4)
+	assertClose(t, "Rho", fit.Rho, testSVI.Rho, 1e-3)
+	assertClose(t, "M", fit.M, testSVI.M, 1e-3)
+	assertClose(t, "Sigma", fit.Sigma, testSVI.Sigma, 1e-3)
+	assertClose(t, "RMSE", rmse, 0, 1e-6)
+}
+
+func TestFitSVITooFewPoints(t *testing.T) {
+	k := []float64{-0.1, 0, 0.1, 0.2}
+	w := []float64{0.05, 0.04, 0.045, 0.05}
+	if _, _, ok := FitSVI(k, w); ok {
+		t.Error("expected no fit with fewer than 5 points")
+	}
+}
+
+func TestNelderMead(t *testing.T) {
+	f := func(x []float64) float64 {
+		return math.Pow(x[0]-1, 2) + 10*math.Pow(x[1]+2, 2)
+	}
+	x := nelderMead(f, []float64{0, 0}, 500)
+	assertClose(t, "x", x[0], 1, 1e-4)
+	assertClose(t, "y", x[1], -2, 1e-4)
+}
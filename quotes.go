@@ -0,0 +1,95 @@
+package tradier
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Maximum number of symbols to request in a single quotes request.
+const maxQuoteBatchSize = 100
+
+type QuoteBatchOptions struct {
+	// Number of symbols per request. Defaults to 100.
+	BatchSize int
+	// Maximum number of concurrent requests.
+	Concurrency int
+	// Skip option greeks, which makes requests faster.
+	NoGreeks bool
+}
+
+type QuoteBatch struct {
+	// Quotes in the order their symbols were requested.
+	Quotes []*Quote
+	// Symbols that Tradier did not recognize or returned no quote for,
+	// in the order they were requested.
+	Unmatched []string
+}
+
+// BySymbol returns the quotes keyed by symbol.
+func (qb *QuoteBatch) BySymbol() map[string]*Quote {
+	return QuotesBySymbol(qb.Quotes)
+}
+
+// GetQuotesBatch fetches quotes for an arbitrarily large list of symbols,
+// splitting it into batches that are requested concurrently. Symbols are
+// matched case-insensitively, and duplicate symbols (in any case) are
+// dropped, so there is at most one quote per symbol.
+func (tc *Client) GetQuotesBatch(symbols []string, opts QuoteBatchOptions) (*QuoteBatch, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = maxQuoteBatchSize
+	}
+
+	// Drop duplicates, keeping the first occurrence.
+	seen := make(map[string]bool, len(symbols))
+	unique := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		key := strings.ToUpper(symbol)
+		if symbol != "" && !seen[key] {
+			seen[key] = true
+			unique = append(unique, symbol)
+		}
+	}
+
+	nBatches := (len(unique) + batchSize - 1) / batchSize
+	quotes := make([][]*Quote, nBatches)
+	errs := make([]error, nBatches)
+	parallelDo(nBatches, opts.Concurrency, func(i int) {
+		end := (i + 1) * batchSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		quotes[i], errs[i] = tc.getQuotes(unique[i*batchSize:end], !opts.NoGreeks)
+	})
+
+	bySymbol := make(map[string]*Quote, len(unique))
+	for i := range quotes {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("error fetching quotes: %v", errs[i])
+		}
+		for _, q := range quotes[i] {
+			bySymbol[strings.ToUpper(q.Symbol)] = q
+		}
+	}
+
+	// Symbols without a quote, whether or not Tradier reported them
+	// as unmatched, in the order they were requested.
+	result := &QuoteBatch{}
+	for _, symbol := range unique {
+		if q, ok := bySymbol[strings.ToUpper(symbol)]; ok {
+			result.Quotes = append(result.Quotes, q)
+		} else {
+			result.Unmatched = append(result.Unmatched, symbol)
+		}
+	}
+	return result, nil
+}
+
+// Fetch quotes for the given symbols and return them keyed by symbol.
+func (tc *Client) getQuotesChunked(symbols []string) (map[string]*Quote, error) {
+	batch, err := tc.GetQuotesBatch(symbols, QuoteBatchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return batch.BySymbol(), nil
+}
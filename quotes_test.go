@@ -0,0 +1,74 @@
+package tradier
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetQuotesBatchMatching(t *testing.T) {
+	var requested string
+	tc := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.FormValue("symbols")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"quotes":{"quote":{"symbol":"AAPL","type":"stock","last":150.0},` +
+			`"unmatched_symbols":{"symbol":"BOGUS"}}}`))
+	}))
+
+	batch, err := tc.GetQuotesBatch([]string{"aapl", "AAPL", "MSFT", "BOGUS"}, QuoteBatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requested != "aapl,MSFT,BOGUS" {
+		t.Errorf("expected duplicate symbols to be dropped, requested %q", requested)
+	}
+	if len(batch.Quotes) != 1 || batch.Quotes[0].Symbol != "AAPL" {
+		t.Errorf("expected the AAPL quote to match aapl, got %v", batch.Quotes)
+	}
+	if expected := []string{"MSFT", "BOGUS"}; !reflect.DeepEqual(batch.Unmatched, expected) {
+		t.Errorf("expected unmatched %v, got %v", expected, batch.Unmatched)
+	}
+}
+
+func TestGetQuotesBatchOrder(t *testing.T) {
+	symbols := []string{"A", "BAD1", "C", "BAD2", "E", "BAD3"}
+	tc := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested := strings.Split(r.FormValue("symbols"), ",")
+		// Finish later batches first.
+		for i, symbol := range symbols {
+			if symbol == requested[0] {
+				time.Sleep(time.Duration(len(symbols)-i) * 10 * time.Millisecond)
+			}
+		}
+
+		var quotes, unmatched []string
+		for _, symbol := range requested {
+			if strings.HasPrefix(symbol, "BAD") {
+				unmatched = append(unmatched, fmt.Sprintf("%q", symbol))
+			} else {
+				quotes = append(quotes, fmt.Sprintf(`{"symbol":%q,"type":"stock","last":1.0}`, symbol))
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"quotes":{"quote":[%s],"unmatched_symbols":{"symbol":[%s]}}}`,
+			strings.Join(quotes, ","), strings.Join(unmatched, ","))
+	}))
+
+	batch, err := tc.GetQuotesBatch(symbols, QuoteBatchOptions{BatchSize: 2, Concurrency: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var quoted []string
+	for _, q := range batch.Quotes {
+		quoted = append(quoted, q.Symbol)
+	}
+	if expected := []string{"A", "C", "E"}; !reflect.DeepEqual(quoted, expected) {
+		t.Errorf("expected quotes for %v, got %v", expected, quoted)
+	}
+	if expected := []string{"BAD1", "BAD2", "BAD3"}; !reflect.DeepEqual(batch.Unmatched, expected) {
+		t.Errorf("expected unmatched %v, got %v", expected, batch.Unmatched)
+	}
+}
@@ -0,0 +1,173 @@
+package tradier
+
+import (
+	"math"
+	"time"
+)
+
+// SessionOf returns the trading session that t falls in on the given
+// day: MarketPremarket, MarketOpen, MarketPostmarket or MarketClosed.
+func SessionOf(t time.Time, day MarketCalendar) MarketState {
+	return ParseTradingDay(day).SessionAt(t).State
+}
+
+// Index the calendar by date in the market timezone.
+func calendarByDate(calendar []MarketCalendar) map[string]MarketCalendar {
+	days := make(map[string]MarketCalendar, len(calendar))
+	for _, day := range calendar {
+		days[day.Date.Format("2006-01-02")] = day
+	}
+	return days
+}
+
+// FilterSession returns the bars that fall within any of the given
+// sessions, according to the calendar. Bars on days that are not
+// in the calendar are dropped.
+func FilterSession(bars []Bar, calendar []MarketCalendar, sessions ...MarketState) []Bar {
+	days := calendarByDate(calendar)
+	var result []Bar
+	for _, bar := range bars {
+		day, ok := days[bar.Time.In(MarketLocation()).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+
+		session := SessionOf(bar.Time, day)
+		for _, s := range sessions {
+			if session == s {
+				result = append(result, bar)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Resample aggregates bars into bars of the given period, such as
+// 2 minutes or 4 hours. Periods are aligned to midnight in the market
+// timezone, so that e.g. hourly bars start on the hour (local time) even
+// across daylight saving changes. Periods longer than a day are treated
+// as a day. Each bar is labeled with the start of its period.
+func Resample(bars []Bar, period time.Duration) []Bar {
+	if period > 24*time.Hour {
+		period = 24 * time.Hour
+	}
+
+	return resample(bars, func(t time.Time) time.Time {
+		t = t.In(MarketLocation())
+		y, m, d := t.Date()
+		sinceMidnight := time.Duration(t.Hour())*time.Hour +
+			time.Duration(t.Minute())*time.Minute +
+			time.Duration(t.Second())*time.Second
+		// Label by wall clock: adding the offset to midnight would be an
+		// hour out on days when the clocks change.
+		offset := sinceMidnight / period * period
+		return time.Date(y, m, d, int(offset/time.Hour), int(offset%time.Hour/time.Minute),
+			int(offset%time.Minute/time.Second), 0, MarketLocation())
+	})
+}
+
+// ResampleSession aggregates the bars within a session into bars of the
+// given period, aligned to the start of the session. The last bar of each
+// session may be shorter than period. If period is zero, there is one bar
+// for each session. Bars outside of the session are dropped.
+func ResampleSession(bars []Bar, calendar []MarketCalendar, session MarketState, period time.Duration) []Bar {
+	days := calendarByDate(calendar)
+	bars = FilterSession(bars, calendar, session)
+
+	return resample(bars, func(t time.Time) time.Time {
+		day := days[t.In(MarketLocation()).Format("2006-01-02")]
+		start := sessionOpen(day, session)
+		if period <= 0 {
+			return start
+		}
+		return start.Add(t.Sub(start) / period * period)
+	})
+}
+
+// The start of the given session on the day.
+func sessionOpen(day MarketCalendar, session MarketState) time.Time {
+	hhmm := day.Open.Start
+	switch session {
+	case MarketPremarket:
+		hhmm = day.Premarket.Start
+	case MarketPostmarket:
+		hhmm = day.Postmarket.Start
+	}
+
+	t, err := parseSessionTime(day.Date.Time, hhmm)
+	if err != nil {
+		d := day.Date.Time
+		return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, MarketLocation())
+	}
+	return t
+}
+
+// Aggregate consecutive bars with the same bucket into a single bar.
+// Bars must be sorted by time.
+func resample(bars []Bar, bucket func(time.Time) time.Time) []Bar {
+	var result []Bar
+	var current Bar
+	var vwapVolume float64
+	for _, bar := range bars {
+		start := bucket(bar.Time)
+		if len(result) == 0 || !start.Equal(current.Time) {
+			if len(result) > 0 {
+				result[len(result)-1] = finishBar(current, vwapVolume)
+			}
+			current = Bar{
+				Symbol: bar.Symbol,
+				Time:   start,
+				Open:   math.NaN(),
+				High:   math.NaN(),
+				Low:    math.NaN(),
+				Close:  math.NaN(),
+			}
+			vwapVolume = 0
+			result = append(result, current)
+		}
+
+		mergeBar(&current, bar, &vwapVolume)
+	}
+
+	if len(result) > 0 {
+		result[len(result)-1] = finishBar(current, vwapVolume)
+	}
+	return result
+}
+
+// Add bar to the aggregate, skipping NaN prices. The aggregate's Vwap
+// holds the running sum of price * volume until finishBar is called.
+func mergeBar(agg *Bar, bar Bar, vwapVolume *float64) {
+	if math.IsNaN(agg.Open) && !math.IsNaN(bar.Open) {
+		agg.Open = bar.Open
+	}
+	if !math.IsNaN(bar.High) && (math.IsNaN(agg.High) || bar.High > agg.High) {
+		agg.High = bar.High
+	}
+	if !math.IsNaN(bar.Low) && (math.IsNaN(agg.Low) || bar.Low < agg.Low) {
+		agg.Low = bar.Low
+	}
+	if !math.IsNaN(bar.Close) {
+		agg.Close = bar.Close
+	}
+	agg.Volume += bar.Volume
+
+	price := bar.Vwap
+	if math.IsNaN(price) || price == 0 {
+		price = bar.Close
+	}
+	if !math.IsNaN(price) && bar.Volume > 0 {
+		agg.Vwap += price * float64(bar.Volume)
+		*vwapVolume += float64(bar.Volume)
+	}
+}
+
+func finishBar(agg Bar, vwapVolume float64) Bar {
+	if vwapVolume > 0 {
+		agg.Vwap /= vwapVolume
+	} else {
+		agg.Vwap = math.NaN()
+	}
+	return agg
+}
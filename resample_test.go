@@ -0,0 +1,71 @@
+package tradier
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResampleAcrossDaylightSaving(t *testing.T) {
+	loc := MarketLocation()
+	bar := func(day, hour, minute int, close float64) Bar {
+		return Bar{
+			Symbol: "SPY",
+			Time:   time.Date(2024, 3, day, hour, minute, 0, 0, loc),
+			Open:   close,
+			High:   close,
+			Low:    close,
+			Close:  close,
+			Volume: 100,
+		}
+	}
+	// Clocks go forward from 02:00 EST to 03:00 EDT on March 10, 2024,
+	// so that day is only 23 hours long.
+	bars := []Bar{
+		bar(10, 1, 30, 1),
+		bar(10, 3, 30, 2),
+		bar(10, 4, 30, 3),
+		bar(10, 5, 30, 4),
+		bar(10, 9, 30, 5),
+		bar(10, 23, 30, 6),
+		bar(11, 0, 30, 7),
+	}
+
+	testCases := []struct {
+		period time.Duration
+		times  []time.Time
+		closes []float64
+	}{
+		{
+			4 * time.Hour,
+			[]time.Time{
+				time.Date(2024, 3, 10, 0, 0, 0, 0, loc),
+				time.Date(2024, 3, 10, 4, 0, 0, 0, loc),
+				time.Date(2024, 3, 10, 8, 0, 0, 0, loc),
+				time.Date(2024, 3, 10, 20, 0, 0, 0, loc),
+				time.Date(2024, 3, 11, 0, 0, 0, 0, loc),
+			},
+			[]float64{2, 4, 5, 6, 7},
+		},
+		{
+			24 * time.Hour,
+			[]time.Time{
+				time.Date(2024, 3, 10, 0, 0, 0, 0, loc),
+				time.Date(2024, 3, 11, 0, 0, 0, 0, loc),
+			},
+			[]float64{6, 7},
+		},
+	}
+
+	for _, tc := range testCases {
+		result := Resample(bars, tc.period)
+		if len(result) != len(tc.times) {
+			t.Fatalf("%v: expected %d bars, got %d", tc.period, len(tc.times), len(result))
+		}
+		for i, b := range result {
+			if !b.Time.Equal(tc.times[i]) {
+				t.Errorf("%v: expected bar %d at %v, got %v", tc.period, i, tc.times[i], b.Time)
+			}
+			assertFloat(t, "close", b.Close, tc.closes[i])
+		}
+	}
+}
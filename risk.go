@@ -0,0 +1,288 @@
+package tradier
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Default benchmark used for beta-weighting.
+const DefaultBenchmark = "SPY"
+
+// Exposure aggregates the greeks of a set of positions.
+// Delta and gamma are in shares of the underlying, theta is in
+// dollars per day, and vega is in dollars per 1 point change in IV.
+type Exposure struct {
+	Underlying      string
+	UnderlyingPrice float64
+	Beta            float64
+
+	Delta             float64
+	Gamma             float64
+	Theta             float64
+	Vega              float64
+	DollarDelta       float64
+	BetaWeightedDelta float64 // In shares of the benchmark.
+
+	Positions []*PortfolioPosition
+}
+
+func (e *Exposure) add(other *Exposure) {
+	e.Delta += other.Delta
+	e.Gamma += other.Gamma
+	e.Theta += other.Theta
+	e.Vega += other.Vega
+	e.DollarDelta += other.DollarDelta
+	e.BetaWeightedDelta += other.BetaWeightedDelta
+	e.Positions = append(e.Positions, other.Positions...)
+}
+
+// RiskReport is the greeks exposure of an account, per underlying and in total.
+type RiskReport struct {
+	Benchmark      string
+	BenchmarkPrice float64
+	Portfolio      *Portfolio
+	ByUnderlying   map[string]*Exposure
+	Total          Exposure
+}
+
+// Shock is a hypothetical market move.
+type Shock struct {
+	// Fractional change in the underlying price, i.e. -0.03 for a 3% drop.
+	Price float64
+	// Change in implied volatility, in points, i.e. 5 for +5 vol.
+	Vol float64
+}
+
+// ScenarioGrid is the P&L of a set of positions under a grid of shocks.
+// PL[i][j] is the P&L for PriceShocks[i] and VolShocks[j].
+type ScenarioGrid struct {
+	Underlying  string
+	PriceShocks []float64
+	VolShocks   []float64
+	PL          [][]float64
+}
+
+// Get the greeks exposure of the selected account. Deltas are beta-weighted
+// against the given benchmark (DefaultBenchmark if empty), using betas
+// from GetRatios. Underlyings without a beta are assumed to have beta 1.
+func (tc *Client) GetRiskReport(benchmark string) (*RiskReport, error) {
+	if benchmark == "" {
+		benchmark = DefaultBenchmark
+	}
+
+	portfolio, err := tc.GetPortfolio()
+	if err != nil {
+		return nil, err
+	}
+
+	underlyings := []string{benchmark}
+	seen := map[string]bool{benchmark: true}
+	for _, p := range portfolio.Positions {
+		u := positionUnderlying(p)
+		if !seen[u] {
+			seen[u] = true
+			underlyings = append(underlyings, u)
+		}
+	}
+
+	quotes, err := tc.getQuotesChunked(underlyings)
+	if err != nil {
+		return nil, err
+	}
+	prices := make(map[string]float64, len(quotes))
+	for symbol, q := range quotes {
+		prices[symbol] = QuoteMark(q)
+	}
+
+	ratios, err := tc.GetRatios(underlyings)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRiskReport(portfolio, prices, BetasFromRatios(ratios), benchmark)
+}
+
+// BetasFromRatios extracts the beta of each requested symbol from a
+// GetRatios response, preferring the 60 month period when there are several.
+func BetasFromRatios(ratios GetRatiosResponse) map[string]float64 {
+	betas := make(map[string]float64)
+	for _, r := range ratios {
+		if r.Error != "" {
+			continue
+		}
+
+		for _, result := range r.Results {
+			periods := make([]string, 0, len(result.Tables.AlphaBeta))
+			for period := range result.Tables.AlphaBeta {
+				periods = append(periods, period)
+			}
+			sort.Strings(periods)
+
+			for _, period := range periods {
+				ab := result.Tables.AlphaBeta[period]
+				if ab.Beta == nil {
+					continue
+				}
+				_, found := betas[r.Request]
+				if !found || (ab.Period != nil && *ab.Period == "60M") {
+					betas[r.Request] = *ab.Beta
+				}
+			}
+		}
+	}
+
+	return betas
+}
+
+// NewRiskReport aggregates the greeks of the positions in the given portfolio.
+// prices are the current prices of the underlyings (and benchmark), and
+// betas are relative to the benchmark. Stock positions without a price
+// are valued at their mark; an error is returned if there is no price
+// for the underlying of an option position, or for the benchmark.
+func NewRiskReport(portfolio *Portfolio, prices, betas map[string]float64, benchmark string) (*RiskReport, error) {
+	if prices[benchmark] <= 0 && len(portfolio.Positions) > 0 {
+		return nil, fmt.Errorf("no price for benchmark %v", benchmark)
+	}
+
+	report := &RiskReport{
+		Benchmark:      benchmark,
+		BenchmarkPrice: prices[benchmark],
+		Portfolio:      portfolio,
+		ByUnderlying:   make(map[string]*Exposure),
+		Total:          Exposure{Beta: 1},
+	}
+
+	for _, p := range portfolio.Positions {
+		u := positionUnderlying(p)
+		exposure, ok := report.ByUnderlying[u]
+		if !ok {
+			beta, ok := betas[u]
+			if !ok || u == benchmark {
+				beta = 1
+			}
+			exposure = &Exposure{
+				Underlying:      u,
+				UnderlyingPrice: prices[u],
+				Beta:            beta,
+			}
+			report.ByUnderlying[u] = exposure
+		}
+		if exposure.UnderlyingPrice <= 0 && !p.IsOption() {
+			exposure.UnderlyingPrice = p.Mark
+		}
+
+		exposure.add(positionExposure(p, exposure.UnderlyingPrice, exposure.Beta, report.BenchmarkPrice))
+	}
+
+	for _, u := range report.Underlyings() {
+		exposure := report.ByUnderlying[u]
+		if exposure.UnderlyingPrice <= 0 {
+			return nil, fmt.Errorf("no price for underlying %v", u)
+		}
+		report.Total.add(exposure)
+	}
+	report.Total.Underlying = benchmark
+	report.Total.UnderlyingPrice = report.BenchmarkPrice
+
+	return report, nil
+}
+
+func positionUnderlying(p *PortfolioPosition) string {
+	if p.IsOption() {
+		return p.Underlying
+	}
+	return p.Symbol
+}
+
+func positionExposure(p *PortfolioPosition, price, beta, benchmarkPrice float64) *Exposure {
+	e := &Exposure{Positions: []*PortfolioPosition{p}}
+	size := p.Quantity * p.Multiplier
+	if p.IsOption() {
+		if p.Greeks != nil {
+			e.Delta = p.Greeks.Delta * size
+			e.Gamma = p.Greeks.Gamma * size
+			e.Theta = p.Greeks.Theta * size
+			e.Vega = p.Greeks.Vega * size
+		}
+	} else {
+		e.Delta = size
+	}
+
+	e.DollarDelta = e.Delta * price
+	if benchmarkPrice > 0 {
+		e.BetaWeightedDelta = beta * e.DollarDelta / benchmarkPrice
+	}
+	return e
+}
+
+// Underlyings returns the underlyings with positions, in sorted order.
+func (r *RiskReport) Underlyings() []string {
+	result := make([]string, 0, len(r.ByUnderlying))
+	for u := range r.ByUnderlying {
+		result = append(result, u)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Scenario estimates the P&L of the positions in the given underlying if its
+// price and implied volatility were shocked. Positions in other underlyings
+// are not affected, even if underlying is the benchmark; see AccountScenario.
+//
+// P&L is estimated with a second order expansion in the greeks, so
+// it is most accurate for small moves.
+func (r *RiskReport) Scenario(underlying string, shock Shock) float64 {
+	e, ok := r.ByUnderlying[underlying]
+	if !ok {
+		return 0
+	}
+	return e.scenario(shock)
+}
+
+// AccountScenario estimates the P&L of the whole account if the benchmark
+// moved by shock.Price, with each underlying moving by beta times the
+// benchmark move, and all implied volatilities changed by shock.Vol.
+func (r *RiskReport) AccountScenario(shock Shock) float64 {
+	var pl float64
+	for _, e := range r.ByUnderlying {
+		pl += e.scenario(Shock{Price: e.Beta * shock.Price, Vol: shock.Vol})
+	}
+	return pl
+}
+
+// ScenarioGrid computes Scenario for every combination of the given
+// price and volatility shocks.
+func (r *RiskReport) ScenarioGrid(underlying string, priceShocks, volShocks []float64) *ScenarioGrid {
+	return newScenarioGrid(underlying, priceShocks, volShocks, func(shock Shock) float64 {
+		return r.Scenario(underlying, shock)
+	})
+}
+
+// AccountScenarioGrid computes AccountScenario for every combination
+// of the given benchmark price and volatility shocks.
+func (r *RiskReport) AccountScenarioGrid(priceShocks, volShocks []float64) *ScenarioGrid {
+	return newScenarioGrid(r.Benchmark, priceShocks, volShocks, r.AccountScenario)
+}
+
+func newScenarioGrid(underlying string, priceShocks, volShocks []float64, pl func(Shock) float64) *ScenarioGrid {
+	grid := &ScenarioGrid{
+		Underlying:  underlying,
+		PriceShocks: priceShocks,
+		VolShocks:   volShocks,
+		PL:          make([][]float64, len(priceShocks)),
+	}
+
+	for i, dp := range priceShocks {
+		grid.PL[i] = make([]float64, len(volShocks))
+		for j, dv := range volShocks {
+			grid.PL[i][j] = pl(Shock{Price: dp, Vol: dv})
+		}
+	}
+
+	return grid
+}
+
+func (e *Exposure) scenario(shock Shock) float64 {
+	dS := e.UnderlyingPrice * shock.Price
+	return e.Delta*dS + 0.5*e.Gamma*dS*dS + e.Vega*shock.Vol
+}
@@ -0,0 +1,320 @@
+package tradier
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// Maximum number of legs in a multileg order.
+	maxMultilegLegs = 4
+	// Maximum number of legs in a combo order.
+	maxComboLegs = 3
+)
+
+// Check that a multileg or combo order is well formed before we send it.
+func validateMultilegOrder(order Order) error {
+	if order.Symbol == "" {
+		return fmt.Errorf("%v order requires an underlying symbol", order.Class)
+	}
+	switch order.Type {
+	case MarketOrder, Debit, Credit, Even:
+	case LimitOrder, StopOrder, StopLimitOrder:
+		if order.Class == Multileg {
+			return fmt.Errorf("multileg orders must be market, debit, credit or even, not %v", order.Type)
+		}
+	default:
+		return fmt.Errorf("unknown order type: %v", order.Type)
+	}
+	if (order.Type == Debit || order.Type == Credit) && order.Price <= 0 {
+		return fmt.Errorf("%v order requires a net price", order.Type)
+	}
+
+	maxLegs := maxMultilegLegs
+	if order.Class == Combo {
+		maxLegs = maxComboLegs
+	}
+	if len(order.Legs) < 2 || len(order.Legs) > maxLegs {
+		return fmt.Errorf("%v order must have between 2 and %d legs, got %d",
+			order.Class, maxLegs, len(order.Legs))
+	}
+
+	equityLegs := 0
+	for i, leg := range order.Legs {
+		if err := checkLegUnderlying(order.Symbol, leg); err != nil {
+			return fmt.Errorf("leg %d: %v", i, err)
+		}
+		if leg.Quantity <= 0 {
+			return fmt.Errorf("leg %d: quantity must be positive", i)
+		}
+		if SideSign(leg.Side) == 0 {
+			return fmt.Errorf("leg %d: unknown side %v", i, leg.Side)
+		}
+		if leg.OptionSymbol == "" {
+			equityLegs++
+			if leg.Side != Buy && leg.Side != Sell && leg.Side != SellShort && leg.Side != BuyToCover {
+				return fmt.Errorf("leg %d: invalid side for equity leg: %v", i, leg.Side)
+			}
+		} else if leg.Side != BuyToOpen && leg.Side != BuyToClose &&
+			leg.Side != SellToOpen && leg.Side != SellToClose {
+			return fmt.Errorf("leg %d: invalid side for option leg: %v", i, leg.Side)
+		}
+	}
+
+	if order.Class == Multileg && equityLegs > 0 {
+		return fmt.Errorf("multileg orders may only contain option legs; use a combo order")
+	}
+	if order.Class == Combo && equityLegs != 1 {
+		return fmt.Errorf("combo orders must contain exactly one equity leg, got %d", equityLegs)
+	}
+
+	return nil
+}
+
+// Check that a leg of a multileg or combo order is on the order's underlying.
+func checkLegUnderlying(underlying string, leg Order) error {
+	if leg.Symbol != "" && !sameSymbol(leg.Symbol, underlying) {
+		return fmt.Errorf("symbol %v does not match order underlying %v", leg.Symbol, underlying)
+	}
+	if leg.OptionSymbol == "" {
+		return nil
+	}
+
+	sym, err := ParseOptionSymbol(leg.OptionSymbol)
+	if err != nil {
+		return err
+	}
+	if !sameSymbol(sym.Underlying(), underlying) {
+		return fmt.Errorf("option %v is not on order underlying %v", leg.OptionSymbol, underlying)
+	}
+	return nil
+}
+
+// Compare symbols ignoring share class punctuation, since option
+// roots omit it (e.g. BRKB options on BRK.B).
+func sameSymbol(a, b string) bool {
+	strip := strings.NewReplacer(".", "", "/", "")
+	return strip.Replace(a) == strip.Replace(b)
+}
+
+// Build the option symbol for a contract on the given underlying.
+func optionSymbol(underlying string, expiration time.Time, optionType string, strike float64) string {
+	return OptionSymbol{
+		Root:       underlying,
+		Expiration: expiration,
+		OptionType: optionType,
+		Strike:     strike,
+	}.String()
+}
+
+// Build an opening option leg. Positive quantities buy and negative quantities sell.
+func optionLeg(underlying string, expiration time.Time, optionType string, strike, quantity float64) Order {
+	side := BuyToOpen
+	if quantity < 0 {
+		side = SellToOpen
+		quantity = -quantity
+	}
+
+	return Order{
+		Symbol:       underlying,
+		OptionSymbol: optionSymbol(underlying, expiration, optionType, strike),
+		Side:         side,
+		Quantity:     quantity,
+	}
+}
+
+func newMultilegOrder(underlying string, legs ...Order) Order {
+	return Order{
+		Class:    Multileg,
+		Symbol:   underlying,
+		Type:     MarketOrder,
+		Duration: Day,
+		Legs:     legs,
+	}
+}
+
+// NewVerticalSpread returns an order opening a vertical spread that is long
+// the option at longStrike and short the option at shortStrike.
+func NewVerticalSpread(underlying string, expiration time.Time, optionType string,
+	longStrike, shortStrike, quantity float64) Order {
+	return newMultilegOrder(underlying,
+		optionLeg(underlying, expiration, optionType, longStrike, quantity),
+		optionLeg(underlying, expiration, optionType, shortStrike, -quantity))
+}
+
+// NewCalendarSpread returns an order opening a calendar spread that is
+// short the near expiration and long the far expiration at the same strike.
+func NewCalendarSpread(underlying string, nearExpiration, farExpiration time.Time,
+	optionType string, strike, quantity float64) Order {
+	return NewDiagonalSpread(underlying, optionType,
+		nearExpiration, strike, farExpiration, strike, quantity)
+}
+
+// NewDiagonalSpread returns an order opening a diagonal spread that is
+// short the near expiration at nearStrike and long the far expiration at farStrike.
+func NewDiagonalSpread(underlying, optionType string,
+	nearExpiration time.Time, nearStrike float64,
+	farExpiration time.Time, farStrike float64, quantity float64) Order {
+	return newMultilegOrder(underlying,
+		optionLeg(underlying, nearExpiration, optionType, nearStrike, -quantity),
+		optionLeg(underlying, farExpiration, optionType, farStrike, quantity))
+}
+
+// NewStraddle returns an order opening a straddle at the given strike.
+// Positive quantities buy the straddle and negative quantities sell it.
+func NewStraddle(underlying string, expiration time.Time, strike, quantity float64) Order {
+	return NewStrangle(underlying, expiration, strike, strike, quantity)
+}
+
+// NewStrangle returns an order opening a strangle with the given put and call strikes.
+// Positive quantities buy the strangle and negative quantities sell it.
+func NewStrangle(underlying string, expiration time.Time, putStrike, callStrike, quantity float64) Order {
+	return newMultilegOrder(underlying,
+		optionLeg(underlying, expiration, Put, putStrike, quantity),
+		optionLeg(underlying, expiration, Call, callStrike, quantity))
+}
+
+// NewIronCondor returns an order opening an iron condor that is short the
+// putShort/callShort strikes and long the putLong/callLong wings.
+// Positive quantities sell the condor (for a credit) and negative quantities buy it.
+func NewIronCondor(underlying string, expiration time.Time,
+	putLong, putShort, callShort, callLong, quantity float64) Order {
+	return newMultilegOrder(underlying,
+		optionLeg(underlying, expiration, Put, putLong, quantity),
+		optionLeg(underlying, expiration, Put, putShort, -quantity),
+		optionLeg(underlying, expiration, Call, callShort, -quantity),
+		optionLeg(underlying, expiration, Call, callLong, quantity))
+}
+
+// NewButterfly returns an order opening a butterfly that is long the wings
+// and short twice as many contracts at the middle strike.
+// Positive quantities buy the butterfly and negative quantities sell it.
+func NewButterfly(underlying string, expiration time.Time, optionType string,
+	lower, middle, upper, quantity float64) Order {
+	return newMultilegOrder(underlying,
+		optionLeg(underlying, expiration, optionType, lower, quantity),
+		optionLeg(underlying, expiration, optionType, middle, -2*quantity),
+		optionLeg(underlying, expiration, optionType, upper, quantity))
+}
+
+// NewCoveredCall returns a combo order that buys shares of the underlying
+// and sells calls against them, one contract per 100 shares.
+func NewCoveredCall(underlying string, expiration time.Time, strike, contracts float64) Order {
+	return Order{
+		Class:    Combo,
+		Symbol:   underlying,
+		Type:     MarketOrder,
+		Duration: Day,
+		Legs: []Order{
+			{Symbol: underlying, Side: Buy, Quantity: contracts * defaultContractSize},
+			optionLeg(underlying, expiration, Call, strike, -contracts),
+		},
+	}
+}
+
+// StrategyPrice is the net price of one unit of a multileg or combo order.
+// Positive prices are debits and negative prices are credits.
+type StrategyPrice struct {
+	// Buying at the ask and selling at the bid.
+	Natural float64
+	// Trading every leg at the mid.
+	Mid float64
+	// Buying at the bid and selling at the ask.
+	Far float64
+}
+
+// NetPriceType returns Debit, Credit or Even for the given net price.
+func NetPriceType(price float64) string {
+	switch {
+	case price > 0:
+		return Debit
+	case price < 0:
+		return Credit
+	}
+	return Even
+}
+
+// PriceStrategy computes the net price of the given multileg or combo order
+// from the quotes of its legs, keyed by symbol (e.g. from GetOptionChain).
+// The price is per unit of the strategy, i.e. leg quantities are divided
+// by their greatest common divisor.
+func PriceStrategy(order Order, quotes map[string]*Quote) (StrategyPrice, error) {
+	var price StrategyPrice
+	unit := strategyUnit(order.Legs)
+	for _, leg := range order.Legs {
+		symbol := legSymbol(leg)
+		q, ok := quotes[symbol]
+		if !ok || q == nil {
+			return price, fmt.Errorf("no quote for leg %v", symbol)
+		}
+
+		// Equity legs are priced per share, option legs per contract,
+		// so normalize equity quantities to contracts.
+		ratio := leg.Quantity / unit
+		if leg.OptionSymbol == "" {
+			ratio = leg.Quantity / defaultContractSize / unit
+		}
+		sign := SideSign(leg.Side)
+		buy, sell := q.Ask, q.Bid
+		if sign < 0 {
+			buy, sell = sell, buy
+		}
+
+		price.Natural += sign * ratio * buy
+		price.Mid += sign * ratio * QuoteMark(q)
+		price.Far += sign * ratio * sell
+	}
+
+	return price, nil
+}
+
+// Price the given multileg or combo order at the given net price,
+// setting its Type to Debit, Credit or Even accordingly.
+func WithNetPrice(order Order, price float64) Order {
+	order.Type = NetPriceType(price)
+	order.Price = math.Abs(roundPrice(price, 0.01))
+	return order
+}
+
+// QuotesBySymbol indexes the given quotes by symbol.
+func QuotesBySymbol(quotes []*Quote) map[string]*Quote {
+	result := make(map[string]*Quote, len(quotes))
+	for _, q := range quotes {
+		result[q.Symbol] = q
+	}
+	return result
+}
+
+// The number of units of the strategy in the order: the greatest common
+// divisor of the leg quantities (in contracts).
+func strategyUnit(legs []Order) float64 {
+	var unit int64
+	for _, leg := range legs {
+		q := int64(leg.Quantity)
+		if leg.OptionSymbol == "" {
+			q /= defaultContractSize
+		}
+		unit = gcd(unit, q)
+	}
+	if unit <= 0 {
+		return 1
+	}
+	return float64(unit)
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Round a price to the nearest multiple of tick.
+func roundPrice(price, tick float64) float64 {
+	return math.Round(price/tick) * tick
+}
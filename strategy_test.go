@@ -0,0 +1,251 @@
+package tradier
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type expectedLeg struct {
+	optionSymbol string // Empty for equity legs.
+	side         string
+	quantity     string
+}
+
+var (
+	testNear = time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)
+	testFar  = time.Date(2024, 7, 19, 0, 0, 0, 0, time.UTC)
+)
+
+type strategyTestCase struct {
+	name  string
+	order Order
+	class string
+	legs  []expectedLeg
+}
+
+var strategyTestCases = []strategyTestCase{
+	{
+		name:  "vertical",
+		order: NewVerticalSpread("SPY", testNear, Call, 500, 510, 2),
+		class: Multileg,
+		legs: []expectedLeg{
+			{"SPY240621C00500000", BuyToOpen, "2"},
+			{"SPY240621C00510000", SellToOpen, "2"},
+		},
+	},
+	{
+		name:  "calendar",
+		order: NewCalendarSpread("SPY", testNear, testFar, Put, 495, 1),
+		class: Multileg,
+		legs: []expectedLeg{
+			{"SPY240621P00495000", SellToOpen, "1"},
+			{"SPY240719P00495000", BuyToOpen, "1"},
+		},
+	},
+	{
+		name:  "diagonal",
+		order: NewDiagonalSpread("SPY", Call, testNear, 505, testFar, 500, 3),
+		class: Multileg,
+		legs: []expectedLeg{
+			{"SPY240621C00505000", SellToOpen, "3"},
+			{"SPY240719C00500000", BuyToOpen, "3"},
+		},
+	},
+	{
+		name:  "long straddle",
+		order: NewStraddle("SPY", testNear, 500, 1),
+		class: Multileg,
+		legs: []expectedLeg{
+			{"SPY240621P00500000", BuyToOpen, "1"},
+			{"SPY240621C00500000", BuyToOpen, "1"},
+		},
+	},
+	{
+		name:  "short strangle",
+		order: NewStrangle("SPY", testNear, 490, 510.5, -4),
+		class: Multileg,
+		legs: []expectedLeg{
+			{"SPY240621P00490000", SellToOpen, "4"},
+			{"SPY240621C00510500", SellToOpen, "4"},
+		},
+	},
+	{
+		name:  "iron condor",
+		order: NewIronCondor("SPY", testNear, 480, 490, 510, 520, 1),
+		class: Multileg,
+		legs: []expectedLeg{
+			{"SPY240621P00480000", BuyToOpen, "1"},
+			{"SPY240621P00490000", SellToOpen, "1"},
+			{"SPY240621C00510000", SellToOpen, "1"},
+			{"SPY240621C00520000", BuyToOpen, "1"},
+		},
+	},
+	{
+		name:  "butterfly",
+		order: NewButterfly("SPY", testNear, Call, 490, 500, 510, 5),
+		class: Multileg,
+		legs: []expectedLeg{
+			{"SPY240621C00490000", BuyToOpen, "5"},
+			{"SPY240621C00500000", SellToOpen, "10"},
+			{"SPY240621C00510000", BuyToOpen, "5"},
+		},
+	},
+	{
+		name:  "covered call",
+		order: NewCoveredCall("SPY", testNear, 510, 3),
+		class: Combo,
+		legs: []expectedLeg{
+			{"", Buy, "300"},
+			{"SPY240621C00510000", SellToOpen, "3"},
+		},
+	},
+}
+
+func TestStrategyOrderParams(t *testing.T) {
+	for _, tc := range strategyTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			form, err := orderToParams(tc.order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkLegParams(t, form, tc.class, tc.legs)
+		})
+	}
+}
+
+// Place each strategy through the client and check what Tradier receives.
+func TestStrategyOrderRoundTrip(t *testing.T) {
+	for _, tc := range strategyTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received url.Values
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Error(err)
+				}
+				received = r.PostForm
+				fmt.Fprint(w, `{"order": {"id": 257459, "status": "ok"}}`)
+			}))
+
+			order := WithNetPrice(tc.order, 1.25)
+			orderId, err := client.PlaceOrder(order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if orderId != 257459 {
+				t.Errorf("order id: got %v, expected 257459", orderId)
+			}
+
+			checkLegParams(t, received, tc.class, tc.legs)
+			if got := received.Get("type"); got != Debit {
+				t.Errorf("type: got %v, expected %v", got, Debit)
+			}
+			if got := received.Get("price"); got != "1.25" {
+				t.Errorf("price: got %v, expected 1.25", got)
+			}
+		})
+	}
+}
+
+func checkLegParams(t *testing.T, form url.Values, class string, legs []expectedLeg) {
+	t.Helper()
+	if got := form.Get("class"); got != class {
+		t.Errorf("class: got %v, expected %v", got, class)
+	}
+	if got := form.Get("symbol"); got != "SPY" {
+		t.Errorf("symbol: got %v, expected SPY", got)
+	}
+
+	for i, leg := range legs {
+		optionKey := fmt.Sprintf("option_symbol[%d]", i)
+		if leg.optionSymbol == "" {
+			if _, ok := form[optionKey]; ok {
+				t.Errorf("equity leg %d has %v = %v", i, optionKey, form.Get(optionKey))
+			}
+		} else if got := form.Get(optionKey); got != leg.optionSymbol {
+			t.Errorf("%v: got %v, expected %v", optionKey, got, leg.optionSymbol)
+		}
+		if got := form.Get(fmt.Sprintf("side[%d]", i)); got != leg.side {
+			t.Errorf("side[%d]: got %v, expected %v", i, got, leg.side)
+		}
+		if got := form.Get(fmt.Sprintf("quantity[%d]", i)); got != leg.quantity {
+			t.Errorf("quantity[%d]: got %v, expected %v", i, got, leg.quantity)
+		}
+	}
+
+	// There should be no parameters for legs beyond those expected.
+	for key := range form {
+		if i := strings.Index(key, "["); i >= 0 {
+			var index int
+			if _, err := fmt.Sscanf(key[i:], "[%d]", &index); err != nil || index >= len(legs) {
+				t.Errorf("unexpected parameter %v", key)
+			}
+		}
+	}
+}
+
+func TestValidateMultilegOrder(t *testing.T) {
+	vertical := NewVerticalSpread("SPY", testNear, Call, 500, 510, 1)
+
+	mixedOption := NewVerticalSpread("SPY", testNear, Call, 500, 510, 1)
+	mixedOption.Legs[1].OptionSymbol = "QQQ240621C00510000"
+
+	mixedSymbol := NewVerticalSpread("SPY", testNear, Call, 500, 510, 1)
+	mixedSymbol.Legs[1].Symbol = "QQQ"
+
+	mixedCombo := NewCoveredCall("SPY", testNear, 510, 1)
+	mixedCombo.Legs[0].Symbol = "QQQ"
+
+	noLegs := vertical
+	noLegs.Legs = nil
+
+	oneLeg := vertical
+	oneLeg.Legs = vertical.Legs[:1]
+
+	zeroQuantity := NewVerticalSpread("SPY", testNear, Call, 500, 510, 1)
+	zeroQuantity.Legs[0].Quantity = 0
+
+	unknownSide := NewVerticalSpread("SPY", testNear, Call, 500, 510, 1)
+	unknownSide.Legs[0].Side = "hold"
+
+	equitySideOnOption := NewVerticalSpread("SPY", testNear, Call, 500, 510, 1)
+	equitySideOnOption.Legs[0].Side = Buy
+
+	optionSideOnEquity := NewCoveredCall("SPY", testNear, 510, 1)
+	optionSideOnEquity.Legs[0].Side = BuyToOpen
+
+	equityInMultileg := NewCoveredCall("SPY", testNear, 510, 1)
+	equityInMultileg.Class = Multileg
+
+	invalid := map[string]Order{
+		"mixed option underlyings": mixedOption,
+		"mixed leg symbols":        mixedSymbol,
+		"mixed combo symbols":      mixedCombo,
+		"no legs":                  noLegs,
+		"one leg":                  oneLeg,
+		"zero quantity":            zeroQuantity,
+		"unknown side":             unknownSide,
+		"equity side on option":    equitySideOnOption,
+		"option side on equity":    optionSideOnEquity,
+		"equity leg in multileg":   equityInMultileg,
+	}
+	for name, order := range invalid {
+		if err := validateMultilegOrder(order); err == nil {
+			t.Errorf("%v: expected error", name)
+		}
+	}
+
+	// Weekly index roots are on the index.
+	spx := NewVerticalSpread("SPX", testNear, Put, 5000, 4990, 1)
+	for i := range spx.Legs {
+		spx.Legs[i].OptionSymbol = "SPXW" + strings.TrimPrefix(spx.Legs[i].OptionSymbol, "SPX")
+	}
+	for _, tc := range append(strategyTestCases, strategyTestCase{name: "spxw", order: spx}) {
+		if err := validateMultilegOrder(tc.order); err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.name, err)
+		}
+	}
+}
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/timpalpant/go-tradier"
 )
 
+// How long the kill switch waits for cancels to be confirmed before flattening.
+const cancelTimeout = 30 * time.Second
+
 func showPositions(client *tradier.Client) {
 	fmt.Println("Fetching current positions")
 	positions, err := client.GetAccountPositions()
@@ -104,10 +111,126 @@ func history(client *tradier.Client) {
 	}
 }
 
+func killSwitch(client *tradier.Client, symbols string, flatten bool, orderType string, confirm bool) {
+	filter := tradier.KillSwitchFilter{}
+	if symbols != "" {
+		filter.Symbols = strings.Split(symbols, ",")
+	}
+
+	if !confirm {
+		fmt.Println("Dry run: pass -confirm to cancel orders and flatten positions")
+		openOrders, err := client.GetOpenOrders()
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, o := range openOrders {
+			if tradier.IsOrderOpen(o) && filter.MatchesOrder(o) {
+				fmt.Printf("would cancel order %v: %v %v %v\n", o.Id, o.Side, o.Quantity, o.Symbol)
+			}
+		}
+		if flatten {
+			positions, err := client.GetAccountPositions()
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, p := range positions {
+				if p.Quantity != 0 && filter.MatchesSymbol(p.Symbol) {
+					fmt.Printf("would close %v %v\n", p.Quantity, p.Symbol)
+				}
+			}
+		}
+		return
+	}
+
+	fmt.Println("Canceling open orders")
+	cancels, err := client.CancelAllOrders(filter)
+	if err != nil {
+		log.Fatal(err)
+	}
+	failed := 0
+	for _, c := range cancels {
+		if c.Err != nil {
+			failed++
+			fmt.Printf("FAILED to cancel order %v (%v): %v\n", c.Order.Id, c.Order.Symbol, c.Err)
+		} else {
+			fmt.Printf("canceled order %v (%v)\n", c.Order.Id, c.Order.Symbol)
+		}
+	}
+
+	if flatten {
+		// Shares committed to orders that are still being canceled
+		// cannot be closed, so wait for the cancels to go through.
+		if err := client.WaitForCancels(cancels, cancelTimeout); err != nil {
+			failed++
+			fmt.Printf("WARNING: %v\n", err)
+		}
+
+		fmt.Println("Flattening positions")
+		closes, err := client.FlattenPositions(filter, orderType)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, c := range closes {
+			if c.Err != nil {
+				failed++
+				fmt.Printf("FAILED to close %v %v: %v\n", c.Position.Quantity, c.Position.Symbol, c.Err)
+			} else {
+				fmt.Printf("placed order %v: %v %v %v\n", c.OrderId, c.Order.Side, c.Order.Quantity, c.Position.Symbol)
+			}
+		}
+	}
+
+	if failed > 0 {
+		log.Fatalf("%d operations failed", failed)
+	}
+}
+
+func chainAnalytics(client *tradier.Client, symbol string, maxDTE int, asJSON bool) {
+	if symbol == "" {
+		log.Fatal("chain requires -symbols")
+	}
+
+	chain, err := client.QueryOptionChain(symbol, tradier.ChainQuery{MaxDTE: maxDTE})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := chain.SetExpirationCloses(tradier.NewTradingCalendar(client)); err != nil {
+		log.Println("WARNING: unable to look up early closes:", err)
+	}
+	analytics := chain.Analytics(time.Now())
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(analytics); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	fmt.Printf("%v: spot $ %.2f, put/call volume %.2f, put/call OI %.2f\n",
+		analytics.Underlying, analytics.Spot, analytics.PutCall.VolumeRatio, analytics.PutCall.OpenInterestRatio)
+	fmt.Printf("net gamma: $ %.0f per 1%%, zero gamma: $ %.2f\n", analytics.NetGamma, analytics.ZeroGamma)
+	for _, ea := range analytics.Expirations {
+		fmt.Printf("%v: max pain $ %.2f, put/call volume %.2f, put/call OI %.2f",
+			ea.Expiration.Format("2006-01-02"), ea.MaxPain, ea.PutCall.VolumeRatio, ea.PutCall.OpenInterestRatio)
+		if ea.ExpectedMove != nil {
+			fmt.Printf(", expected move +/- $ %.2f (%.1f %%)", ea.ExpectedMove.Straddle, 100*ea.ExpectedMove.Percent)
+		}
+		fmt.Println()
+	}
+}
+
 func main() {
-	subcommand := flag.String("command", "positions", "Command to run (positions, gainloss, openorders)")
+	subcommand := flag.String("command", "positions", "Command to run (positions, gainloss, openorders, killswitch, chain)")
 	apiKey := flag.String("tradier.apikey", "", "Tradier API key")
 	account := flag.String("tradier.account", "", "Tradier account ID")
+	symbols := flag.String("symbols", "", "Comma-separated symbols to restrict killswitch to (default all), or the underlying for chain")
+	flatten := flag.Bool("flatten", false, "Also close positions in killswitch")
+	orderType := flag.String("ordertype", tradier.MarketOrder, "Order type used to flatten positions (market, limit)")
+	confirm := flag.Bool("confirm", false, "Actually cancel and flatten in killswitch (otherwise a dry run)")
+	maxDTE := flag.Int("maxdte", 60, "Maximum days to expiration included in chain")
+	asJSON := flag.Bool("json", false, "Print chain analytics as JSON")
 	flag.Parse()
 
 	params := tradier.DefaultParams(*apiKey)
@@ -123,6 +246,10 @@ func main() {
 		openOrders(client)
 	case "history":
 		history(client)
+	case "killswitch":
+		killSwitch(client, *symbols, *flatten, *orderType, *confirm)
+	case "chain":
+		chainAnalytics(client, *symbols, *maxDTE, *asJSON)
 	default:
 		log.Fatal("unknown command: ", *subcommand)
 	}
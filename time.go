@@ -2,7 +2,12 @@ package tradier
 
 import (
 	"strconv"
+	"sync"
 	"time"
+
+	// Embedded timezone data, so that the market timezone can be
+	// loaded on systems without a zoneinfo database.
+	_ "time/tzdata"
 )
 
 // DateTime wraps time.Time and adds flexible implementations for unmarshaling
@@ -72,3 +77,32 @@ func ParseTimeMs(tsMs string) (time.Time, error) {
 	t := time.Unix(secs, nsecs)
 	return t, nil
 }
+
+var (
+	newYorkOnce sync.Once
+	newYork     *time.Location
+)
+
+// MarketLocation returns the timezone of US equity and option markets,
+// America/New_York, in which Tradier reports session times and
+// intraday time sales.
+func MarketLocation() *time.Location {
+	newYorkOnce.Do(func() {
+		var err error
+		newYork, err = time.LoadLocation("America/New_York")
+		if err != nil {
+			panic(err)
+		}
+	})
+	return newYork
+}
+
+// Return the time of day given as "15:04" on the given date, in the market timezone.
+func parseSessionTime(date time.Time, hhmm string) (time.Time, error) {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return time.Time{}, err
+	}
+	y, m, d := date.Date()
+	return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, MarketLocation()), nil
+}